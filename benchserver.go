@@ -0,0 +1,75 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+// NewBenchServer constructs and starts a Server meant to be the downstream in client benchmarks, answering every
+// request with resp. Unlike NewServer, it uses no Handler or testify: the response is rendered once up front,
+// request bodies are drained into pooled buffers, and serving a request allocates nothing in httpmock itself, so the
+// mock doesn't dominate the benchmark's profile. Options don't apply to bench servers.
+func NewBenchServer(resp Response) *Server {
+	s := &Server{httpServer: httptest.NewUnstartedServer(newBenchHandler(resp)), ready: make(chan struct{})}
+	s.Start()
+	return s
+}
+
+// benchHandler is the http.Handler behind NewBenchServer.
+type benchHandler struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func newBenchHandler(resp Response) *benchHandler {
+	body := resp.Body
+	header := cloneHeader(resp.Header)
+	if resp.BodyObject != nil {
+		data, err := json.Marshal(resp.BodyObject)
+		if err != nil {
+			panic(fmt.Sprintf("failed to marshal object %v: %v", resp.BodyObject, err))
+		}
+		body = data
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &benchHandler{status: status, header: header, body: body}
+}
+
+var benchBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 32*1024)
+		return &buf
+	},
+}
+
+// ServeHTTP makes this implement http.Handler
+func (h *benchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	buf := benchBufferPool.Get().(*[]byte)
+	for {
+		if _, err := r.Body.Read(*buf); err != nil {
+			break
+		}
+	}
+	benchBufferPool.Put(buf)
+
+	header := w.Header()
+	for k, v := range h.header {
+		// The values are never modified by net/http, so they can be shared between requests
+		header[k] = v
+	}
+	w.WriteHeader(h.status)
+	_, _ = w.Write(h.body)
+}
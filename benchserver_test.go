@@ -0,0 +1,36 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchServer(t *testing.T) {
+	s := NewBenchServer(Response{Status: 201, BodyObject: map[string]string{"status": "ok"}})
+	defer s.Close()
+
+	resp, err := http.Post(s.URL()+"/anything", "text/plain", strings.NewReader("ignored"))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"status":"ok"}`, string(body))
+}
+
+func BenchmarkBenchHandler(b *testing.B) {
+	h := newBenchHandler(Response{Body: []byte(`{"status": "ok"}`)})
+	req := httptest.NewRequest("POST", "/object/12345", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Package binarymock provides matchers and response helpers for MessagePack and CBOR (RFC 8949) bodies, encoded and
+decoded with vmihailenco/msgpack and fxamacker/cbor. It is a separate module to keep those out of the dependencies of
+httpmock itself.
+
+	downstream.On("Handle", "POST", "/events", binarymock.MsgPackMatcher(event)).
+		Return(binarymock.ToMsgPackResponse(200, ack))
+
+Objects are encoded with json struct tags applying unless a field has a msgpack or cbor tag, and with map keys sorted
+so that encodings are deterministic. Matchers compare in the JSON data model: the encoded object and the body are
+both decoded and converted to their generic JSON form, e.g. float64 for all numbers, so that any valid encoding of the
+object matches, whatever integer or float widths, key order or length encoding it uses. Byte strings are compared as
+base64 strings, like encoding/json represents []byte, and maps with keys other than strings never match.
+*/
+package binarymock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/dankinder/httpmock"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgPackMatcher returns a mock.MatchedBy func to check if the argument is the MessagePack form of the provided
+// object, compare to httpmock.JSONMatcher.
+func MsgPackMatcher(o interface{}) interface{} {
+	return matcher(ToMsgPack(o), decodeMsgPack)
+}
+
+// ToMsgPack is a convenience function for converting an object to MessagePack inline. It panics on failure, so should
+// be used only in test code.
+func ToMsgPack(obj interface{}) []byte {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+	if err := enc.Encode(obj); err != nil {
+		panic(fmt.Sprintf("failed to marshal object %v: %v", obj, err))
+	}
+	return buf.Bytes()
+}
+
+// ToMsgPackResponse returns a Response with the given status whose body is obj encoded with ToMsgPack, and with
+// Content-Type application/msgpack.
+func ToMsgPackResponse(status int, obj interface{}) httpmock.Response {
+	return httpmock.Response{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/msgpack"}},
+		Body:   ToMsgPack(obj),
+	}
+}
+
+// CBORMatcher returns a mock.MatchedBy func to check if the argument is the CBOR form of the provided object, compare
+// to httpmock.JSONMatcher.
+func CBORMatcher(o interface{}) interface{} {
+	return matcher(ToCBOR(o), decodeCBOR)
+}
+
+// ToCBOR is a convenience function for converting an object to CBOR inline, using the core deterministic encoding of
+// RFC 8949. It panics on failure, so should be used only in test code.
+func ToCBOR(obj interface{}) []byte {
+	data, err := cborEncoding.Marshal(obj)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal object %v: %v", obj, err))
+	}
+	return data
+}
+
+// ToCBORResponse returns a Response with the given status whose body is obj encoded with ToCBOR, and with
+// Content-Type application/cbor.
+func ToCBORResponse(status int, obj interface{}) httpmock.Response {
+	return httpmock.Response{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/cbor"}},
+		Body:   ToCBOR(obj),
+	}
+}
+
+var (
+	cborEncoding = mustEncMode(cbor.CoreDetEncOptions())
+	cborDecoding = mustDecMode(cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}(nil))})
+)
+
+func mustEncMode(opts cbor.EncOptions) cbor.EncMode {
+	mode, err := opts.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}
+
+func mustDecMode(opts cbor.DecOptions) cbor.DecMode {
+	mode, err := opts.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}
+
+func decodeMsgPack(data []byte) (interface{}, error) {
+	var v interface{}
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.PeekCode(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return v, nil
+}
+
+func decodeCBOR(data []byte) (interface{}, error) {
+	var v interface{}
+	err := cborDecoding.Unmarshal(data, &v)
+	return v, err
+}
+
+// matcher does the actual work for MsgPackMatcher and CBORMatcher, matching the bodies that decode to the same generic
+// JSON form as encoded.
+func matcher(encoded []byte, decode func([]byte) (interface{}, error)) interface{} {
+	expected, err := toGeneric(encoded, decode)
+	if err != nil {
+		panic(fmt.Sprintf("failed to convert object: %v", err))
+	}
+	return mock.MatchedBy(func(arg []byte) bool {
+		actual, err := toGeneric(arg, decode)
+		if err != nil {
+			// Assume that this call doesn't match us since we couldn't parse it
+			return false
+		}
+		return reflect.DeepEqual(expected, actual)
+	})
+}
+
+// toGeneric decodes data and converts the result to its generic JSON form, e.g. float64 for all numbers.
+func toGeneric(data []byte, decode func([]byte) (interface{}, error)) (interface{}, error) {
+	decoded, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	err = json.Unmarshal(data, &generic)
+	return generic, err
+}
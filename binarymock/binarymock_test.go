@@ -0,0 +1,91 @@
+package binarymock
+
+import (
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// matches reports whether the given expected argument (a value or a matcher) matches the actual argument.
+func matches(expected, actual interface{}) bool {
+	_, diffs := mock.Arguments{expected}.Diff([]interface{}{actual})
+	return diffs == 0
+}
+
+func TestBinaryEncodings(t *testing.T) {
+	type obj struct {
+		A int           `json:"a"`
+		B []interface{} `json:"b"`
+	}
+	o := obj{A: 1, B: []interface{}{true, nil}}
+
+	assert.Equal(t, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x92, 0xc3, 0xc0}, ToMsgPack(o))
+	assert.Equal(t, []byte{0xa2, 0x61, 'a', 0x01, 0x61, 'b', 0x82, 0xf5, 0xf6}, ToCBOR(o))
+	assert.Equal(t, "application/msgpack", ToMsgPackResponse(200, o).Header.Get("Content-Type"))
+	assert.Equal(t, "application/cbor", ToCBORResponse(200, o).Header.Get("Content-Type"))
+
+	assert.True(t, matches(MsgPackMatcher(o), ToMsgPack(o)))
+	assert.True(t, matches(CBORMatcher(o), ToCBOR(o)))
+	assert.False(t, matches(MsgPackMatcher(o), ToMsgPack(obj{A: 2})))
+	assert.False(t, matches(CBORMatcher(o), httpmock.ToJSON(o)))
+	assert.False(t, matches(MsgPackMatcher(o), append(ToMsgPack(o), 0xc0)), "trailing data shouldn't match")
+
+	// Encodings other than our own: a uint16, a float32 and keys out of order
+	assert.True(t, matches(MsgPackMatcher(map[string]float64{"b": 1.5, "a": 300}),
+		[]byte{0x82, 0xa1, 'b', 0xca, 0x3f, 0xc0, 0x00, 0x00, 0xa1, 'a', 0xcd, 0x01, 0x2c}))
+}
+
+// Examples from https://msgpack.org and the MessagePack specification
+func TestMsgPackVectors(t *testing.T) {
+	compact := []byte("\x82\xa7compact\xc3\xa6schema\x00")
+	assert.Equal(t, compact, ToMsgPack(map[string]interface{}{"compact": true, "schema": 0}))
+	assert.True(t, matches(MsgPackMatcher(map[string]interface{}{"schema": 0, "compact": true}), compact))
+
+	for _, v := range []struct {
+		value   interface{}
+		encoded []byte
+	}{
+		{-1, []byte{0xff}},
+		{-33, []byte{0xd0, 0xdf}},
+		{128, []byte{0xcc, 0x80}},
+		{65536, []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{[]byte{1, 2}, []byte{0xc4, 0x02, 0x01, 0x02}},
+	} {
+		assert.Equal(t, v.encoded, ToMsgPack(v.value), "%v", v.value)
+		assert.True(t, matches(MsgPackMatcher(v.value), v.encoded), "%v", v.value)
+	}
+}
+
+// Examples from RFC 8949, Appendix A
+func TestCBORVectors(t *testing.T) {
+	for _, v := range []struct {
+		value   interface{}
+		encoded []byte
+	}{
+		{-1000, []byte{0x39, 0x03, 0xe7}},
+		{1000000, []byte{0x1a, 0x00, 0x0f, 0x42, 0x40}},
+		{1.5, []byte{0xf9, 0x3e, 0x00}},
+		{100000.0, []byte{0xfa, 0x47, 0xc3, 0x50, 0x00}},
+		{1.1, []byte{0xfb, 0x3f, 0xf1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a}},
+		{[]byte{1, 2, 3, 4}, []byte{0x44, 0x01, 0x02, 0x03, 0x04}},
+		{"ü", []byte{0x62, 0xc3, 0xbc}},
+		{map[string]interface{}{"a": 1, "b": []int{2, 3}}, []byte{0xa2, 0x61, 'a', 0x01, 0x61, 'b', 0x82, 0x02, 0x03}},
+		{[]interface{}{"a", map[string]string{"b": "c"}}, []byte{0x82, 0x61, 'a', 0xa1, 0x61, 'b', 0x61, 'c'}},
+	} {
+		assert.Equal(t, v.encoded, ToCBOR(v.value), "%v", v.value)
+		assert.True(t, matches(CBORMatcher(v.value), v.encoded), "%v", v.value)
+	}
+
+	// Indefinite-length encodings of {"a": 1, "b": [2, 3]} and ["a", {"b": "c"}]
+	assert.True(t, matches(CBORMatcher(map[string]interface{}{"a": 1, "b": []int{2, 3}}),
+		[]byte{0xbf, 0x61, 'a', 0x01, 0x61, 'b', 0x9f, 0x02, 0x03, 0xff, 0xff}))
+	assert.True(t, matches(CBORMatcher([]interface{}{"a", map[string]string{"b": "c"}}),
+		[]byte{0x9f, 0x61, 'a', 0xbf, 0x61, 'b', 0x61, 'c', 0xff, 0xff}))
+	// Floats match integers of the same value: 1.0 as a half-precision float
+	assert.True(t, matches(CBORMatcher(1), []byte{0xf9, 0x3c, 0x00}))
+	// Maps with keys other than strings never match: {1: 2, 3: 4}
+	assert.False(t, matches(CBORMatcher(map[string]int{"1": 2, "3": 4}), []byte{0xa2, 0x01, 0x02, 0x03, 0x04}))
+}
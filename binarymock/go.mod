@@ -0,0 +1,21 @@
+module github.com/dankinder/httpmock/binarymock
+
+go 1.20
+
+require (
+	github.com/dankinder/httpmock v0.0.0
+	github.com/fxamacker/cbor/v2 v2.9.0
+	github.com/stretchr/testify v1.8.4
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.5.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/dankinder/httpmock => ../
@@ -0,0 +1,49 @@
+package httpmock
+
+// BodyReadPolicy is how a server handles errors reading request bodies, such as a client disconnecting during an
+// upload. Whatever the policy, the error is passed to the function set with OnBodyReadError, if any.
+type BodyReadPolicy int
+
+const (
+	// BodyReadErrorContinue logs the error and calls the Handler with the part of the body that was read, and the
+	// error in RequestInfo.BodyErr. This is the default.
+	BodyReadErrorContinue BodyReadPolicy = iota
+	// BodyReadErrorReject answers 400 Bad Request without calling the Handler.
+	BodyReadErrorReject
+	// BodyReadErrorReport reports the error like other errors, failing the test given to ReportErrorsTo, and then
+	// calls the Handler like BodyReadErrorContinue.
+	BodyReadErrorReport
+)
+
+// BodyReadErrors sets how the server handles errors reading request bodies.
+func BodyReadErrors(policy BodyReadPolicy) Option {
+	return func(s *Server) {
+		s.bodyErrors = policy
+	}
+}
+
+// OnBodyReadError sets a function called with the request and the error whenever reading a request body fails, in
+// addition to the server's BodyReadPolicy.
+func OnBodyReadError(fn func(req RequestInfo, err error)) Option {
+	return func(s *Server) {
+		s.onBodyError = fn
+	}
+}
+
+// handleBodyError handles the error reading the body of req according to the server's policy, reporting whether the
+// Handler should still be called.
+func (s *Server) handleBodyError(req RequestInfo) bool {
+	if s.onBodyError != nil {
+		s.onBodyError(req, req.BodyErr)
+	}
+	switch s.bodyErrors {
+	case BodyReadErrorReject:
+		return false
+	case BodyReadErrorReport:
+		s.reportError("failed to read body of %s %s: %v", req.Method, req.Path, req.BodyErr)
+	default:
+		s.log().Warn("httpmock: failed to read request body", "method", req.Method, "path", req.Path,
+			"error", req.BodyErr)
+	}
+	return true
+}
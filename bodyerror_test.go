@@ -0,0 +1,44 @@
+package httpmock
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBodyReadErrors(t *testing.T) {
+	errDisconnected := errors.New("disconnected")
+	failingRequest := func() *http.Request {
+		body := io.MultiReader(strings.NewReader("part"), iotest.ErrReader(errDisconnected))
+		return httptest.NewRequest("POST", "/upload", body)
+	}
+
+	downstream := &MockHandlerWithRequest{}
+	downstream.On("HandleRequest", mock.Anything).Return(Response{Status: 201})
+
+	var hooked []error
+	rt := &recordingT{}
+	s := NewUnstartedServer(downstream, ReportErrorsTo(rt), BodyReadErrors(BodyReadErrorReport),
+		OnBodyReadError(func(req RequestInfo, err error) { hooked = append(hooked, err) }))
+	w := httptest.NewRecorder()
+	s.HTTPHandler().ServeHTTP(w, failingRequest())
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, []error{errDisconnected}, hooked)
+	assert.Equal(t, []string{"httpmock: failed to read body of POST /upload: disconnected"}, rt.Errors())
+	req := downstream.Calls[0].Arguments.Get(0).(RequestInfo)
+	assert.Equal(t, "part", string(req.Body))
+	assert.Equal(t, errDisconnected, req.BodyErr)
+
+	s = NewUnstartedServer(downstream, BodyReadErrors(BodyReadErrorReject))
+	w = httptest.NewRecorder()
+	s.HTTPHandler().ServeHTTP(w, failingRequest())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	downstream.AssertNumberOfCalls(t, "HandleRequest", 1)
+}
@@ -0,0 +1,160 @@
+package httpmock
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallbackServer is a started Server for receiving webhooks sent by the code under test. It records every delivery,
+// verifies their signatures if given a secret, can answer with failures to exercise the sender's retries, and lets
+// tests wait for deliveries to arrive.
+//
+//	receiver := httpmock.NewCallbackServer()
+//	defer receiver.Close()
+//	receiver.Secret = "whsec"
+//	receiver.FailNext(2, http.StatusServiceUnavailable)
+//	// ... configure the code under test to send webhooks to receiver.URL()
+//	deliveries, err := receiver.WaitForDeliveries(3, time.Second)
+type CallbackServer struct {
+	*Server
+
+	// Secret, if set, is the key of the HMAC-SHA256 signature of the body that deliveries must carry, hex-encoded
+	// and optionally prefixed with "sha256=", in the SignatureHeader. Deliveries without a valid signature are
+	// answered with a 401. Set it before deliveries are made.
+	Secret string
+	// SignatureHeader is the header holding the signature (default: "X-Signature-256").
+	SignatureHeader string
+
+	mu         sync.Mutex
+	deliveries []CallbackDelivery
+	failures   []int
+	notify     chan struct{}
+}
+
+// CallbackDelivery is a webhook delivery received by a CallbackServer.
+type CallbackDelivery struct {
+	RequestInfo
+	// When the delivery was received
+	Time time.Time
+	// Whether the delivery carried a valid signature (always true if the server has no Secret)
+	SignatureValid bool
+	// The status the server answered with
+	Status int
+}
+
+// NewCallbackServer constructs and starts a CallbackServer. Options apply as for NewServer.
+func NewCallbackServer(opts ...Option) *CallbackServer {
+	c := &CallbackServer{notify: make(chan struct{})}
+	c.Server = NewServer(callbackHandler{c}, opts...)
+	return c
+}
+
+// FailNext makes the server answer the next n valid deliveries with the given status, e.g. 503, so that the sender
+// retries them.
+func (c *CallbackServer) FailNext(n int, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i < n; i++ {
+		c.failures = append(c.failures, status)
+	}
+}
+
+// Deliveries returns the deliveries received so far, in the order they arrived.
+func (c *CallbackServer) Deliveries() []CallbackDelivery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CallbackDelivery(nil), c.deliveries...)
+}
+
+// WaitForDeliveries waits until at least n deliveries have been received, returning them all, or returns an error
+// if that doesn't happen within timeout.
+func (c *CallbackServer) WaitForDeliveries(n int, timeout time.Duration) ([]CallbackDelivery, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		if len(c.deliveries) >= n {
+			defer c.mu.Unlock()
+			return append([]CallbackDelivery(nil), c.deliveries...), nil
+		}
+		notify := c.notify
+		got := len(c.deliveries)
+		c.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, fmt.Errorf("received %d webhook deliveries within %s, expected %d", got, timeout, n)
+		}
+	}
+}
+
+// WaitForDelivery waits for the first delivery to be received and returns it, or returns an error if none is received
+// within timeout.
+func (c *CallbackServer) WaitForDelivery(timeout time.Duration) (CallbackDelivery, error) {
+	deliveries, err := c.WaitForDeliveries(1, timeout)
+	if err != nil {
+		return CallbackDelivery{}, err
+	}
+	return deliveries[0], nil
+}
+
+// validSignature checks the signature of a delivery.
+func (c *CallbackServer) validSignature(req RequestInfo) bool {
+	if c.Secret == "" {
+		return true
+	}
+	header := c.SignatureHeader
+	if header == "" {
+		header = "X-Signature-256"
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(req.Header.Get(header), "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	mac.Write(req.Body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// callbackHandler is the HandlerWithRequest of a CallbackServer.
+type callbackHandler struct {
+	c *CallbackServer
+}
+
+// Handle makes this implement the Handler interface.
+func (h callbackHandler) Handle(method, path string, body []byte) Response {
+	return h.HandleRequest(RequestInfo{Method: method, Path: path, Header: http.Header{}, Body: body})
+}
+
+// HandleRequest makes this implement the HandlerWithRequest interface.
+func (h callbackHandler) HandleRequest(req RequestInfo) Response {
+	c := h.c
+	valid := c.validSignature(req)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := http.StatusOK
+	switch {
+	case !valid:
+		status = http.StatusUnauthorized
+	case len(c.failures) > 0:
+		status = c.failures[0]
+		c.failures = c.failures[1:]
+	}
+	c.deliveries = append(c.deliveries, CallbackDelivery{
+		RequestInfo:    req,
+		Time:           time.Now(),
+		SignatureValid: valid,
+		Status:         status,
+	})
+	close(c.notify)
+	c.notify = make(chan struct{})
+	return Response{Status: status}
+}
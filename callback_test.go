@@ -0,0 +1,48 @@
+package httpmock
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackServer(t *testing.T) {
+	receiver := NewCallbackServer()
+	defer receiver.Close()
+	receiver.Secret = "whsec"
+	receiver.FailNext(1, http.StatusServiceUnavailable)
+
+	mac := hmac.New(sha256.New, []byte("whsec"))
+	mac.Write([]byte(`{"paid":true}`))
+	hook := &Webhook{
+		URL:           receiver.URL() + "/hooks",
+		Header:        http.Header{"X-Signature-256": {"sha256=" + hex.EncodeToString(mac.Sum(nil))}},
+		MaxAttempts:   3,
+		RetryInterval: time.Millisecond,
+	}
+	hook.Trigger([]byte(`{"paid":true}`))
+
+	deliveries, err := receiver.WaitForDeliveries(2, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].Status)
+	assert.Equal(t, http.StatusOK, deliveries[1].Status)
+	assert.True(t, deliveries[1].SignatureValid)
+	assert.Equal(t, "/hooks", deliveries[1].Path)
+
+	// An unsigned delivery is rejected
+	resp, err := http.Post(receiver.URL()+"/hooks", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.False(t, receiver.Deliveries()[2].SignatureValid)
+
+	_, err = receiver.WaitForDeliveries(4, 10*time.Millisecond)
+	assert.EqualError(t, err, "received 3 webhook deliveries within 10ms, expected 4")
+	hook.Wait()
+}
@@ -0,0 +1,100 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Capture returns a mock.MatchedBy func that always matches, storing the argument it is matched against in dst. The
+// type parameter selects the argument, e.g. a *[]byte captures the body, a *http.Header the headers and a
+// *RequestInfo the whole request of HandleRequest. Read dst only after the request has completed; use a Captor when
+// requests may be running concurrently.
+//
+// Note that testify evaluates the matchers of every expectation registered for the method, including ones whose other
+// arguments don't match and ones that were already used up by Once or Times. Capture matchers are therefore best used
+// in a handler with a single expectation per method, or with a Captor that is filtered afterwards.
+//
+//	var body []byte
+//	downstream.On("Handle", "POST", "/upload", httpmock.Capture(&body)).Return(httpmock.Response{})
+func Capture[T any](dst *T) interface{} {
+	return mock.MatchedBy(func(arg T) bool {
+		*dst = arg
+		return true
+	})
+}
+
+// CaptureJSONBody returns a mock.MatchedBy func that always matches, decoding the body argument into dst, which must
+// be a pointer. Bodies that aren't valid JSON leave dst untouched.
+func CaptureJSONBody(dst interface{}) interface{} {
+	return mock.MatchedBy(func(body []byte) bool {
+		_ = json.Unmarshal(body, dst)
+		return true
+	})
+}
+
+// CaptureJSON returns a mock.MatchedBy func that decodes the body argument into a new T and sends it on ch, so a test
+// can receive exactly what the client sent as a typed value. Bodies that can't be decoded into T don't match. The send
+// blocks the request until it is received, so use a buffered channel if the test reads it only after the client is
+// done.
+//
+//	orders := make(chan Order, 1)
+//	downstream.On("Handle", "POST", "/orders", httpmock.CaptureJSON(orders)).Return(httpmock.Response{Status: 201})
+//	// ... run the code under test
+//	order := <-orders
+func CaptureJSON[T any](ch chan<- T) interface{} {
+	return mock.MatchedBy(func(body []byte) bool {
+		var v T
+		if err := json.Unmarshal(body, &v); err != nil {
+			return false
+		}
+		ch <- v
+		return true
+	})
+}
+
+// Captor records every argument its matcher is matched against. It is safe for concurrent use, so it can be read
+// while the server is still handling requests.
+//
+//	bodies := &httpmock.Captor[[]byte]{}
+//	downstream.On("Handle", "POST", "/events", bodies.Matcher()).Return(httpmock.Response{})
+type Captor[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+// Matcher returns a mock.MatchedBy func that always matches, appending the argument to the captor.
+func (c *Captor[T]) Matcher() interface{} {
+	return mock.MatchedBy(func(arg T) bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.values = append(c.values, arg)
+		return true
+	})
+}
+
+// Values returns a copy of all captured arguments, in the order they were captured.
+func (c *Captor[T]) Values() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]T(nil), c.values...)
+}
+
+// Last returns the most recently captured argument, or the zero value if nothing was captured yet.
+func (c *Captor[T]) Last() T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var last T
+	if len(c.values) > 0 {
+		last = c.values[len(c.values)-1]
+	}
+	return last
+}
+
+// Len returns the number of captured arguments.
+func (c *Captor[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.values)
+}
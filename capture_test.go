@@ -0,0 +1,75 @@
+package httpmock
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	downstream := NewMockHandlerWithHeaders(t)
+
+	var headers http.Header
+	bodies := &Captor[[]byte]{}
+	downstream.On("HandleWithHeaders", "POST", "/echo", Capture(&headers), bodies.Matcher()).Return(Response{})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	for _, body := range []string{"hello", "goodbye"} {
+		req, err := http.NewRequest("POST", fmt.Sprintf("%s/echo", s.URL()), strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("HTTPMOCK-TEST", body)
+		_, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "goodbye", headers.Get("HTTPMOCK-TEST"))
+	assert.Equal(t, [][]byte{[]byte("hello"), []byte("goodbye")}, bodies.Values())
+	assert.Equal(t, []byte("goodbye"), bodies.Last())
+
+	downstream.AssertExpectations(t)
+}
+
+func TestCaptureJSONBody(t *testing.T) {
+	downstream := NewMockHandler(t)
+
+	var obj struct {
+		A string `json:"a"`
+	}
+	downstream.On("Handle", "POST", "/json", CaptureJSONBody(&obj)).Return(Response{})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	_, err := http.Post(fmt.Sprintf("%s/json", s.URL()), "application/json", strings.NewReader(`{"a":"aye"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "aye", obj.A)
+	downstream.AssertExpectations(t)
+}
+
+func TestCaptureJSON(t *testing.T) {
+	type order struct {
+		ID    string `json:"id"`
+		Count int    `json:"count"`
+	}
+	downstream := NewMockHandler(t)
+
+	orders := make(chan order, 1)
+	downstream.On("Handle", "POST", "/orders", CaptureJSON(orders)).Return(Response{Status: 201})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	resp, err := http.Post(fmt.Sprintf("%s/orders", s.URL()), "application/json", strings.NewReader(`{"id":"a1","count":3}`))
+	require.NoError(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+
+	assert.Equal(t, order{ID: "a1", Count: 3}, <-orders)
+	downstream.AssertExpectations(t)
+}
@@ -0,0 +1,149 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Cassette is a recording of requests received by a Server and the responses it sent. It can be saved to a JSON file
+// and replayed later by a ReplayHandler, e.g. to capture the behavior of a real upstream once (through a handler that
+// proxies to it) and serve it hermetically afterwards.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	RequestHeader http.Header `json:"request_header,omitempty"`
+	RequestBody   []byte      `json:"request_body,omitempty"`
+
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+
+	// When the request arrived, relative to the first interaction of the cassette
+	Offset time.Duration `json:"offset"`
+	// How long the response took
+	Duration time.Duration `json:"duration"`
+}
+
+// Cassette returns the requests received so far as a Cassette. Requests still being handled are left out. It returns
+// an empty Cassette unless the server was created with the RecordHistory option.
+func (s *Server) Cassette() Cassette {
+	var c Cassette
+	var first time.Time
+	for _, r := range s.History() {
+		if r.End.IsZero() {
+			continue
+		}
+		if first.IsZero() {
+			first = r.Start
+		}
+		status := r.Response.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		c.Interactions = append(c.Interactions, Interaction{
+			Method:        r.Method,
+			Path:          r.Path,
+			RequestHeader: r.Header,
+			RequestBody:   r.Body,
+			Status:        status,
+			Header:        r.Response.Header,
+			Body:          r.Response.Body,
+			Offset:        r.Start.Sub(first),
+			Duration:      r.Duration(),
+		})
+	}
+	return c
+}
+
+// LoadCassette reads a Cassette saved with Cassette.Save.
+func LoadCassette(filename string) (Cassette, error) {
+	var c Cassette
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// Save writes the cassette to a JSON file.
+func (c Cassette) Save(filename string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// ReplayHandler is a Handler that serves the responses of a Cassette. Each request is answered with the first
+// interaction with the same method and path that hasn't been replayed yet; requests without one get a 404.
+type ReplayHandler struct {
+	// TimingScale, if non-zero, makes the handler reproduce the recorded timing of the cassette, scaled by this
+	// factor: each response is delayed by its recorded duration, and isn't sent before it was sent relative to the
+	// start of the recording. E.g. 1 replays in real time and 0.5 twice as fast.
+	TimingScale float64
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	start    time.Time
+}
+
+// NewReplayHandler returns a ReplayHandler replaying c.
+func NewReplayHandler(c Cassette) *ReplayHandler {
+	return &ReplayHandler{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+// Reset makes every interaction available for replay again and restarts the timing, see Server.Reset.
+func (h *ReplayHandler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.used = make([]bool, len(h.cassette.Interactions))
+	h.start = time.Time{}
+}
+
+// Handle makes this implement the Handler interface.
+func (h *ReplayHandler) Handle(method, path string, body []byte) Response {
+	now := time.Now()
+	h.mu.Lock()
+	if h.start.IsZero() {
+		h.start = now
+	}
+	var interaction *Interaction
+	for i := range h.cassette.Interactions {
+		if !h.used[i] && h.cassette.Interactions[i].Method == method && h.cassette.Interactions[i].Path == path {
+			h.used[i] = true
+			interaction = &h.cassette.Interactions[i]
+			break
+		}
+	}
+	start := h.start
+	h.mu.Unlock()
+
+	if interaction == nil {
+		return Response{
+			Status: http.StatusNotFound,
+			Body:   []byte(fmt.Sprintf("httpmock: no recorded interaction left for %s %s", method, path)),
+		}
+	}
+
+	if h.TimingScale > 0 {
+		scale := func(d time.Duration) time.Duration { return time.Duration(float64(d) * h.TimingScale) }
+		deadline := now.Add(scale(interaction.Duration))
+		if paced := start.Add(scale(interaction.Offset + interaction.Duration)); paced.After(deadline) {
+			deadline = paced
+		}
+		time.Sleep(time.Until(deadline))
+	}
+
+	return Response{Status: interaction.Status, Header: interaction.Header, Body: interaction.Body}
+}
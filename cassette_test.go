@@ -0,0 +1,56 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteReplay(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/slow", mock.Anything).Return(Response{Body: []byte("slow")}).
+		Run(func(mock.Arguments) { time.Sleep(50 * time.Millisecond) })
+	downstream.On("Handle", "GET", "/fast", mock.Anything).Return(Response{Status: 202, Body: []byte("fast")})
+
+	recording := NewServer(downstream, RecordHistory())
+	for _, path := range []string{"/fast", "/slow"} {
+		resp, err := http.Get(recording.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	recording.Close()
+
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recording.Cassette().Save(filename))
+	cassette, err := LoadCassette(filename)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 2)
+
+	replay := NewReplayHandler(cassette)
+	replay.TimingScale = 0.5
+	s := NewServer(replay)
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/fast")
+	require.NoError(t, err)
+	assert.Equal(t, 202, resp.StatusCode)
+
+	start := time.Now()
+	resp, err = http.Get(s.URL() + "/slow")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "slow", string(body))
+	assert.GreaterOrEqual(t, time.Since(start), 25*time.Millisecond)
+
+	// Each interaction is only replayed once
+	resp, err = http.Get(s.URL() + "/slow")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,82 @@
+package httpmock
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextResponse returns a Response with the given status whose body is text encoded in charset, with Content-Type
+// text/plain and the charset parameter, for testing how clients decode upstreams that don't use UTF-8. For other media
+// types, set the Content-Type with TextContentType or use EncodeText directly. See EncodeText for the supported
+// charsets; it panics on others.
+func TextResponse(status int, text, charset string) Response {
+	return Response{
+		Status: status,
+		Header: http.Header{"Content-Type": {TextContentType("text/plain", charset)}},
+		Body:   EncodeText(text, charset),
+	}
+}
+
+// TextContentType returns a Content-Type value for mediaType with the given charset parameter, e.g.
+// "text/html; charset=iso-8859-1".
+func TextContentType(mediaType, charset string) string {
+	return mime.FormatMediaType(mediaType, map[string]string{"charset": strings.ToLower(charset)})
+}
+
+// EncodeText encodes text in charset, which is one of (case-insensitively):
+//
+//   - "utf-8"
+//   - "us-ascii"
+//   - "iso-8859-1" or "latin1"
+//   - "utf-16", big-endian with a byte order mark, as per RFC 2781
+//   - "utf-16be" and "utf-16le", without a byte order mark
+//
+// Characters that the charset can't represent are replaced with '?'. It panics on other charsets, so should be used
+// only in test code.
+func EncodeText(text, charset string) []byte {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return []byte(text)
+	case "us-ascii", "ascii":
+		return encodeSingleByte(text, 0x7f)
+	case "iso-8859-1", "latin1":
+		return encodeSingleByte(text, 0xff)
+	case "utf-16":
+		return append([]byte{0xfe, 0xff}, encodeUTF16(text, false)...)
+	case "utf-16be":
+		return encodeUTF16(text, false)
+	case "utf-16le":
+		return encodeUTF16(text, true)
+	default:
+		panic(fmt.Sprintf("httpmock: unsupported charset %q", charset))
+	}
+}
+
+// encodeSingleByte encodes text in a charset whose code points are the first max+1 of Unicode.
+func encodeSingleByte(text string, max rune) []byte {
+	data := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r > max || r == utf8.RuneError {
+			r = '?'
+		}
+		data = append(data, byte(r))
+	}
+	return data
+}
+
+func encodeUTF16(text string, littleEndian bool) []byte {
+	units := utf16.Encode([]rune(text))
+	data := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		if littleEndian {
+			data = append(data, byte(u), byte(u>>8))
+		} else {
+			data = append(data, byte(u>>8), byte(u))
+		}
+	}
+	return data
+}
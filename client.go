@@ -0,0 +1,122 @@
+package httpmock
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Hop is one request made while following redirects, see FollowRedirects.
+type Hop struct {
+	Method string
+	URL    string
+	// The status code of the response to this request
+	StatusCode int
+	// The Location header of the response, if it was a redirect
+	Location string
+}
+
+// FollowRedirects issues req with client, following redirects according to the client's CheckRedirect policy, and
+// returns every hop visited along with the final response. This lets redirect policy tests assert on the whole chain
+// rather than just where it ended. The client itself is not modified.
+func FollowRedirects(client *http.Client, req *http.Request) ([]Hop, *http.Response, error) {
+	var hops []Hop
+	c := *client
+	checkRedirect := client.CheckRedirect
+	if checkRedirect == nil {
+		checkRedirect = defaultCheckRedirect
+	}
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		err := checkRedirect(req, via)
+		if err == nil {
+			hops = append(hops, hopFor(via[len(via)-1], req.Response))
+		}
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if resp != nil {
+		hops = append(hops, hopFor(resp.Request, resp))
+	}
+	return hops, resp, err
+}
+
+// defaultCheckRedirect is the policy used by http.Client when CheckRedirect is nil.
+func defaultCheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+func hopFor(req *http.Request, resp *http.Response) Hop {
+	return Hop{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Location:   resp.Header.Get("Location"),
+	}
+}
+
+// Timing holds the timings of a request made with TraceRequest. Client-side durations are zero for phases that didn't
+// happen, e.g. DNS and Connect when a kept-alive connection was reused.
+type Timing struct {
+	// When the client started the request
+	Start time.Time
+	// Client-side durations of the DNS lookup, TCP connect and TLS handshake
+	DNS, Connect, TLSHandshake time.Duration
+	// Client-side time from Start until the first byte of the response was received
+	TimeToFirstByte time.Duration
+	// Client-side time from Start until the response headers were received
+	Total time.Duration
+	// Whether the client reused a kept-alive connection
+	ConnReused bool
+	// The server's record of the request, which includes its server-side Start and End. It is zero unless the
+	// server was created with the RecordHistory option.
+	Server RecordedRequest
+}
+
+// traceIDHeader is the request header TraceRequest uses to find its request in the server's history.
+const traceIDHeader = "Httpmock-Trace-Id"
+
+var lastTraceID uint64
+
+// TraceRequest issues req against s with client, tracing it with net/http/httptrace, and returns the response along
+// with client-side timings correlated with the server's record of the same request. This lets latency-budget tests
+// tell how much of a request's time was spent in the client, on the network, and in the mock.
+func TraceRequest(s *Server, client *http.Client, req *http.Request) (*http.Response, Timing, error) {
+	var timing Timing
+	var dnsStart, connectStart, tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { timing.DNS = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { timing.Connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { timing.TLSHandshake = time.Since(tlsStart) },
+		GotConn:           func(info httptrace.GotConnInfo) { timing.ConnReused = info.Reused },
+		GotFirstResponseByte: func() {
+			timing.TimeToFirstByte = time.Since(timing.Start)
+		},
+	}
+
+	traceID := strconv.FormatUint(atomic.AddUint64(&lastTraceID, 1), 10)
+	req = req.Clone(httptrace.WithClientTrace(req.Context(), trace))
+	req.Header.Set(traceIDHeader, traceID)
+
+	timing.Start = time.Now()
+	resp, err := client.Do(req)
+	timing.Total = time.Since(timing.Start)
+
+	for _, recorded := range s.History() {
+		if recorded.Header.Get(traceIDHeader) == traceID {
+			timing.Server = recorded
+			break
+		}
+	}
+	return resp, timing, err
+}
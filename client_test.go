@@ -0,0 +1,71 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowRedirects(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "POST", "/old", mock.Anything).Return(Response{
+		Status: http.StatusMovedPermanently,
+		Header: http.Header{"Location": {"/new"}},
+	})
+	downstream.On("Handle", "GET", "/new", mock.Anything).Return(Response{
+		Status: http.StatusFound,
+		Header: http.Header{"Location": {"/final"}},
+	})
+	downstream.On("Handle", "GET", "/final", mock.Anything).Return(Response{Status: http.StatusOK})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	req, err := http.NewRequest("POST", s.URL()+"/old", nil)
+	require.NoError(t, err)
+	hops, resp, err := FollowRedirects(http.DefaultClient, req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []Hop{
+		{Method: "POST", URL: s.URL() + "/old", StatusCode: 301, Location: "/new"},
+		{Method: "GET", URL: s.URL() + "/new", StatusCode: 302, Location: "/final"},
+		{Method: "GET", URL: s.URL() + "/final", StatusCode: 200},
+	}, hops)
+
+	// With a policy refusing redirects, only the first hop is visited
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	req, err = http.NewRequest("POST", s.URL()+"/old", nil)
+	require.NoError(t, err)
+	hops, _, err = FollowRedirects(client, req)
+	require.NoError(t, err)
+	assert.Equal(t, []Hop{{Method: "POST", URL: s.URL() + "/old", StatusCode: 301, Location: "/new"}}, hops)
+
+	downstream.AssertExpectations(t)
+}
+
+func TestTraceRequest(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/slow", mock.Anything).Return(Response{}).
+		Run(func(mock.Arguments) { time.Sleep(20 * time.Millisecond) })
+
+	s := NewServer(downstream, RecordHistory())
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", s.URL()+"/slow", nil)
+	require.NoError(t, err)
+	resp, timing, err := TraceRequest(s, http.DefaultClient, req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, "/slow", timing.Server.Path)
+	assert.GreaterOrEqual(t, timing.Server.Duration(), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, timing.TimeToFirstByte, timing.Server.Duration())
+	assert.GreaterOrEqual(t, timing.Total, timing.TimeToFirstByte)
+	assert.False(t, timing.Server.Start.Before(timing.Start))
+
+	downstream.AssertExpectations(t)
+}
@@ -0,0 +1,85 @@
+package httpmock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Clock tells the time, so that time-dependent behavior such as ActiveBetween can be tested with a FakeClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock telling the real time.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock whose time only changes when told to, with Set or Advance. It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now makes this implement the Clock interface.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set sets the clock's time.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock's time forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// ActiveBetween wraps an argument matcher (or a plain expected value such as mock.Anything) so that it only matches
+// from the time from (inclusive) until the time to (exclusive), as told by clock (SystemClock if nil). A zero from or
+// to leaves that end of the window open. This makes an expectation apply only during a time window, to simulate an
+// upstream whose behavior changes over time, e.g. at a token's expiry:
+//
+//	downstream.On("Handle", "GET", "/me", httpmock.ActiveBetween(clock, time.Time{}, expiry, mock.Anything)).Return(ok)
+//	downstream.On("Handle", "GET", "/me", httpmock.ActiveBetween(clock, expiry, time.Time{}, mock.Anything)).Return(
+//		httpmock.Response{Status: 401})
+func ActiveBetween(clock Clock, from, to time.Time, matcher interface{}) interface{} {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return mock.MatchedBy(func(arg interface{}) bool {
+		now := clock.Now()
+		if (!from.IsZero() && now.Before(from)) || (!to.IsZero() && !now.Before(to)) {
+			return false
+		}
+		_, diffs := mock.Arguments{matcher}.Diff([]interface{}{arg})
+		return diffs == 0
+	})
+}
+
+// ActiveFor wraps an argument matcher like ActiveBetween, making it match only for ttl from now on.
+func ActiveFor(clock Clock, ttl time.Duration, matcher interface{}) interface{} {
+	if clock == nil {
+		clock = SystemClock
+	}
+	now := clock.Now()
+	return ActiveBetween(clock, now, now.Add(ttl), matcher)
+}
@@ -0,0 +1,43 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveBetween(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	expiry := clock.Now().Add(time.Hour)
+
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/me", ActiveFor(clock, time.Hour, mock.Anything)).Return(Response{Status: 200})
+	downstream.On("Handle", "GET", "/me", ActiveBetween(clock, expiry, time.Time{}, mock.Anything)).Return(Response{Status: 401})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	get := func() int {
+		resp, err := http.Get(s.URL() + "/me")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, 200, get())
+	clock.Advance(59 * time.Minute)
+	assert.Equal(t, 200, get())
+	clock.Advance(time.Minute)
+	assert.Equal(t, 401, get())
+
+	m := ActiveBetween(clock, time.Time{}, expiry.Add(time.Hour), []byte("hello"))
+	assert.True(t, matches(m, []byte("hello")))
+	assert.False(t, matches(m, []byte("goodbye")))
+	clock.Set(expiry.Add(time.Hour))
+	assert.False(t, matches(m, []byte("hello")))
+
+	downstream.AssertExpectations(t)
+}
@@ -0,0 +1,105 @@
+package httpmock
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressResponses returns a Middleware gzipping the responses to requests whose Accept-Encoding allows it, like
+// most production servers, adding Vary: Accept-Encoding. Responses that set a Content-Encoding themselves are left
+// alone, so Gzipped and Uncompressed responses force an encoding regardless of Accept-Encoding.
+func CompressResponses() Middleware {
+	return RewriteResponse(func(req RequestInfo, resp Response) Response {
+		if resp.Hijack != nil || resp.uncompressed || resp.Header.Get("Content-Encoding") != "" {
+			return resp
+		}
+		if acceptsGzip(req.Header) {
+			resp = Gzipped(resp)
+			resp.Header.Add("Vary", "Accept-Encoding")
+		}
+		return resp
+	})
+}
+
+// WithCompression makes the server gzip responses when clients accept it, see CompressResponses.
+func WithCompression() Option {
+	return WithMiddleware(CompressResponses())
+}
+
+// Gzipped returns resp with its body (or BodyObject) gzipped and Content-Encoding gzip, whether or not the client
+// accepts it, e.g. to test clients that don't send Accept-Encoding against a misbehaving upstream.
+func Gzipped(resp Response) Response {
+	if resp.compute != nil {
+		return computed(resp, Gzipped)
+	}
+	resp = GzippedWithoutHeader(resp)
+	resp.Header.Set("Content-Encoding", "gzip")
+	return resp
+}
+
+// Uncompressed returns resp marked so that CompressResponses leaves it uncompressed, whatever the client accepts.
+func Uncompressed(resp Response) Response {
+	if resp.compute != nil {
+		return computed(resp, Uncompressed)
+	}
+	resp.uncompressed = true
+	return resp
+}
+
+// GzippedWithoutHeader returns resp with its body (or BodyObject) gzipped but no Content-Encoding, so that clients
+// get compressed bytes they don't know to decompress.
+func GzippedWithoutHeader(resp Response) Response {
+	if resp.compute != nil {
+		return computed(resp, GzippedWithoutHeader)
+	}
+	resp = renderBodyObject(resp)
+	resp.Header = cloneHeader(resp.Header)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(resp.Body)
+	_ = zw.Close()
+	resp.Body = buf.Bytes()
+	return resp
+}
+
+// MislabeledEncoding returns resp with a Content-Encoding of encoding, e.g. "gzip" or "br", while its body is left
+// as is, for testing how clients handle bodies that fail to decompress.
+func MislabeledEncoding(resp Response, encoding string) Response {
+	if resp.compute != nil {
+		return computed(resp, func(resp Response) Response { return MislabeledEncoding(resp, encoding) })
+	}
+	resp.Header = cloneHeader(resp.Header)
+	resp.Header.Set("Content-Encoding", encoding)
+	return resp
+}
+
+// computed returns a Response computed like resp, and then passed through fn.
+func computed(resp Response, fn func(Response) Response) Response {
+	return Response{compute: func(req RequestInfo) Response {
+		return fn(resp.compute(req))
+	}}
+}
+
+// acceptsGzip returns whether a request with the given headers accepts gzip-encoded responses.
+func acceptsGzip(header http.Header) bool {
+	for _, value := range header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+			name = strings.TrimSpace(name)
+			if !strings.EqualFold(name, "gzip") && !strings.EqualFold(name, "x-gzip") && name != "*" {
+				continue
+			}
+			params = strings.TrimSpace(params)
+			if !strings.HasPrefix(params, "q=") {
+				return true
+			}
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64); err != nil || q > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
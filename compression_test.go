@@ -0,0 +1,94 @@
+package httpmock
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func gunzip(t *testing.T, data []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	plain, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return string(plain)
+}
+
+func TestCompressResponses(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/negotiated", mock.Anything).Return(Response{Body: []byte("hello")})
+	downstream.On("Handle", "GET", "/forced", mock.Anything).Return(Gzipped(Response{BodyObject: []int{1}}))
+	downstream.On("Handle", "GET", "/plain", mock.Anything).Return(Uncompressed(Response{Body: []byte("hello")}))
+	s := NewServer(downstream, WithCompression())
+	defer s.Close()
+
+	get := func(path, acceptEncoding string) (*http.Response, []byte) {
+		req, err := http.NewRequest("GET", s.URL()+path, nil)
+		require.NoError(t, err)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	resp, body := get("/negotiated", "br, gzip;q=0.5")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"))
+	assert.Equal(t, "hello", gunzip(t, body))
+	resp, body = get("/negotiated", "gzip;q=0")
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "hello", string(body))
+
+	resp, body = get("/forced", "identity")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "[1]", gunzip(t, body))
+
+	resp, body = get("/plain", "gzip")
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "hello", string(body))
+}
+
+func TestUncompressedWithoutCompression(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/plain", mock.Anything).Return(Uncompressed(Response{Body: []byte("hello")}))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/plain")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, sent := resp.Header["Content-Encoding"]
+	assert.False(t, sent, "the marker shouldn't leak into the response")
+}
+
+func TestMismatchedEncodings(t *testing.T) {
+	resp := MislabeledEncoding(Response{Body: []byte("plain")}, "gzip")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "plain", string(resp.Body))
+
+	resp = GzippedWithoutHeader(Response{Body: []byte("hidden")})
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "hidden", gunzip(t, resp.Body))
+
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(MislabeledEncoding(Response{Body: []byte("plain")}, "gzip"))
+	s := NewServer(downstream)
+	defer s.Close()
+	r, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	defer r.Body.Close()
+	_, err = io.ReadAll(r.Body)
+	assert.Error(t, err, "Go's client should fail to decompress the body")
+}
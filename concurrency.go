@@ -0,0 +1,59 @@
+package httpmock
+
+import (
+	"sync"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// concurrencyLimit limits how many requests an expectation is handling at once, see MockHandler.MaxConcurrent and
+// MockHandler.QueueConcurrent.
+type concurrencyLimit struct {
+	call *mock.Call
+	max  int
+	// The test failed when the limit is exceeded, or nil to queue requests instead
+	t     TestingT
+	slots chan struct{}
+
+	mu     sync.Mutex
+	active int
+}
+
+// limitConcurrency limits the requests call handles at once to max, failing t when they exceed it, or queueing them
+// if t is nil. A request counts while the call's Run function runs, which is wrapped for this: testify only calls it
+// once it has settled on call as the match for the request, so the arguments aren't matched again.
+func limitConcurrency(call *mock.Call, max int, t TestingT) {
+	limit := &concurrencyLimit{call: call, max: max, t: t, slots: make(chan struct{}, max)}
+	run := call.RunFn
+	call.Run(func(args mock.Arguments) {
+		defer limit.acquire()()
+		if run != nil {
+			run(args)
+		}
+	})
+}
+
+// acquire counts a request against the limit, waiting for a slot in queueing mode. The returned func must be called
+// once the request is handled.
+func (l *concurrencyLimit) acquire() (release func()) {
+	if l.t == nil {
+		l.slots <- struct{}{}
+		return func() { <-l.slots }
+	}
+	l.mu.Lock()
+	l.active++
+	active := l.active
+	l.mu.Unlock()
+	if active > l.max {
+		if h, ok := l.t.(interface{ Helper() }); ok {
+			h.Helper()
+		}
+		l.t.Errorf("httpmock: expectation %s(%s) was matched by %d concurrent requests, more than the limit of %d",
+			l.call.Method, formatArgs(l.call.Arguments), active, l.max)
+	}
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.active--
+	}
+}
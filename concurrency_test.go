@@ -0,0 +1,90 @@
+package httpmock
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// concurrentGets sends n concurrent GET requests for path.
+func concurrentGets(s *Server, path string, n int) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, err := http.Get(s.URL() + path); err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	rt := &recordingT{}
+	downstream := &MockHandler{}
+	downstream.MaxConcurrent(rt, downstream.On("Handle", "GET", "/token", mock.Anything).Return(Response{}).
+		Run(func(mock.Arguments) { time.Sleep(100 * time.Millisecond) }), 1)
+	downstream.On("Handle", "GET", "/other", mock.Anything).After(100 * time.Millisecond).Return(Response{})
+	s := NewServer(downstream)
+	defer s.Close()
+
+	concurrentGets(s, "/other", 3)
+	assert.Empty(t, rt.Errors(), "unlimited expectations should allow concurrent requests")
+	concurrentGets(s, "/token", 1)
+	concurrentGets(s, "/token", 1)
+	assert.Empty(t, rt.Errors(), "sequential requests should be within the limit")
+	concurrentGets(s, "/token", 2)
+	assert.Equal(t, []string{"httpmock: expectation Handle(GET, /token, mock.Anything) was matched by 2 concurrent " +
+		"requests, more than the limit of 1"}, rt.Errors())
+}
+
+func TestQueueConcurrent(t *testing.T) {
+	var active, maxActive int32
+	downstream := &MockHandler{}
+	downstream.QueueConcurrent(downstream.On("Handle", "GET", "/token", mock.Anything).Run(func(mock.Arguments) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	}).Return(Response{}), 1)
+	s := NewServer(downstream)
+	defer s.Close()
+
+	concurrentGets(s, "/token", 5)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxActive))
+	downstream.AssertNumberOfCalls(t, "Handle", 5)
+}
+
+func TestMaxConcurrentMatchesOnce(t *testing.T) {
+	var matched int32
+	body := mock.MatchedBy(func([]byte) bool {
+		atomic.AddInt32(&matched, 1)
+		return true
+	})
+	rt := &recordingT{}
+	downstream := &MockHandler{}
+	// The first expectation is the one testify uses for the first request, although only the second one is limited
+	downstream.On("Handle", "GET", "/token", mock.Anything).Return(Response{}).Once()
+	downstream.MaxConcurrent(rt, downstream.On("Handle", "GET", "/token", body).Return(Response{}), 1)
+	s := NewServer(downstream)
+	defer s.Close()
+
+	concurrentGets(s, "/token", 1)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&matched))
+	concurrentGets(s, "/token", 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&matched), "the matcher should run once per request")
+	assert.Empty(t, rt.Errors())
+	downstream.AssertNumberOfCalls(t, "Handle", 2)
+}
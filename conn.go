@@ -0,0 +1,86 @@
+package httpmock
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+type connIDKey struct{}
+
+// connContext numbers the server's connections and makes them available to ServeHTTP, for use as
+// http.Server.ConnContext.
+func (s *Server) connContext(ctx context.Context, c net.Conn) context.Context {
+	ctx = context.WithValue(ctx, connIDKey{}, int(atomic.AddInt64(&s.lastConnID, 1)))
+	return rawHeaderConnContext(ctx, c)
+}
+
+// connID returns the ID of the connection r was received on, or 0 if it is unknown.
+func connID(r *http.Request) int {
+	id, _ := r.Context().Value(connIDKey{}).(int)
+	return id
+}
+
+// localAddr returns the server's address for the connection r was received on, or "" if it is unknown.
+func localAddr(r *http.Request) string {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+// AssertSingleConnection asserts that all the requests received so far came over the same connection, e.g. to check
+// that a client reuses one kept-alive or HTTP/2 connection. It requires the RecordHistory option.
+func (s *Server) AssertSingleConnection(t TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if s.history == nil {
+		t.Errorf("httpmock: AssertSingleConnection requires the RecordHistory option")
+		return false
+	}
+
+	history := s.History()
+	for _, req := range history {
+		if req.ConnID != history[0].ConnID {
+			t.Errorf("httpmock: %s %s came over connection %d from %s, but %s %s came over connection %d from %s",
+				req.Method, req.Path, req.ConnID, req.RemoteAddr, history[0].Method, history[0].Path,
+				history[0].ConnID, history[0].RemoteAddr)
+			return false
+		}
+	}
+	return true
+}
+
+// AssertConnectionReuse asserts that at least minReusedRequests requests were received over a connection that had
+// already served an earlier request, e.g. to check that a client keeps connections alive or pipelines requests. It
+// requires the RecordHistory option.
+func (s *Server) AssertConnectionReuse(t TestingT, minReusedRequests int) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if s.history == nil {
+		t.Errorf("httpmock: AssertConnectionReuse requires the RecordHistory option")
+		return false
+	}
+
+	history := s.History()
+	conns := map[int]bool{}
+	reused := 0
+	for _, req := range history {
+		if req.ConnID == 0 {
+			continue
+		}
+		if conns[req.ConnID] {
+			reused++
+		}
+		conns[req.ConnID] = true
+	}
+	if reused < minReusedRequests {
+		t.Errorf("httpmock: expected at least %d requests over reused connections, got %d (%d requests over %d "+
+			"connections)", minReusedRequests, reused, len(history), len(conns))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertConnectionReuse(t *testing.T) {
+	s := NewServer(&OKHandler{}, RecordHistory())
+	defer s.Close()
+
+	get := func(client *http.Client) {
+		resp, err := client.Get(s.URL() + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	keepAlive := &http.Client{Transport: &http.Transport{}}
+	for i := 0; i < 3; i++ {
+		get(keepAlive)
+	}
+	noKeepAlive := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	get(noKeepAlive)
+	get(noKeepAlive)
+
+	history := s.History()
+	require.Len(t, history, 5)
+	assert.NotZero(t, history[0].ConnID)
+	assert.Equal(t, history[0].ConnID, history[2].ConnID)
+	assert.NotEqual(t, history[3].ConnID, history[4].ConnID)
+
+	assert.True(t, s.AssertConnectionReuse(t, 2))
+	rt := &recordingT{}
+	assert.False(t, s.AssertConnectionReuse(rt, 3))
+	assert.Equal(t, []string{"httpmock: expected at least 3 requests over reused connections, got 2 (5 requests " +
+		"over 3 connections)"}, rt.Errors())
+}
+
+func TestConnectionMetadata(t *testing.T) {
+	s := NewServer(&OKHandler{}, RecordHistory())
+	defer s.Close()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	for i := 0; i < 10; i++ {
+		resp, err := client.Get(s.URL() + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.True(t, s.AssertSingleConnection(t))
+
+	req := s.History()[0]
+	assert.Equal(t, s.httpServer.Listener.Addr().String(), req.LocalAddr)
+	assert.Contains(t, req.RemoteAddr, "127.0.0.1:")
+
+	resp, err := (&http.Client{Transport: &http.Transport{}}).Get(s.URL() + "/other")
+	require.NoError(t, err)
+	resp.Body.Close()
+	rt := &recordingT{}
+	assert.False(t, s.AssertSingleConnection(rt))
+	assert.Len(t, rt.Errors(), 1)
+}
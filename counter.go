@@ -0,0 +1,99 @@
+package httpmock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// counterWindow is how long a counter keeps exact arrival times. Older arrivals are only counted by second, so that
+// memory doesn't grow with every request of a long load test.
+const counterWindow = time.Minute
+
+// counter counts the requests received by a server, keeping their arrival times (and nothing else, unlike history)
+// for RequestsSince. Times are offsets from base, which are monotonic.
+type counter struct {
+	mu      sync.Mutex
+	base    time.Time
+	total   int
+	recent  []time.Duration // Arrivals in the window, in order
+	seconds []int           // Arrivals before recent, by second since base
+	moved   time.Duration   // The last arrival moved to seconds
+}
+
+// add counts a request arriving now.
+func (c *counter) add() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.base.IsZero() {
+		c.base = time.Now()
+	}
+	// Taking the time with the lock held keeps recent in order
+	now := time.Since(c.base)
+	c.total++
+	c.recent = append(c.recent, now)
+
+	// Move arrivals out of the window once they make up half of recent, so that moving is amortized
+	cut := sort.Search(len(c.recent), func(i int) bool { return c.recent[i] >= now-counterWindow })
+	if cut > 0 && cut >= len(c.recent)/2 {
+		for _, d := range c.recent[:cut] {
+			sec := int(d / time.Second)
+			for len(c.seconds) <= sec {
+				c.seconds = append(c.seconds, 0)
+			}
+			c.seconds[sec]++
+		}
+		c.moved = c.recent[cut-1]
+		c.recent = append([]time.Duration(nil), c.recent[cut:]...)
+	}
+}
+
+func (c *counter) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+func (c *counter) since(t time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.base.IsZero() {
+		return 0
+	}
+	offset := t.Sub(c.base)
+	n := len(c.recent) - sort.Search(len(c.recent), func(i int) bool { return c.recent[i] >= offset })
+	if len(c.seconds) == 0 || offset > c.moved {
+		return n
+	}
+	// Count the older arrivals from the second t falls in
+	from := 0
+	if offset > 0 {
+		from = int(offset / time.Second)
+	}
+	for sec := from; sec < len(c.seconds); sec++ {
+		n += c.seconds[sec]
+	}
+	return n
+}
+
+func (c *counter) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.base = time.Time{}
+	c.total = 0
+	c.recent = nil
+	c.seconds = nil
+	c.moved = 0
+}
+
+// TotalRequests returns the number of requests the server has received. Unlike History, it needs no option and
+// retains no request data, so it suits load tests checking that their throughput reached the mock.
+func (s *Server) TotalRequests() int {
+	return s.requests.count()
+}
+
+// RequestsSince returns the number of requests the server has received at or after t. It is exact for t within the
+// last minute; earlier arrivals are only kept by second, so for older t it counts from the start of its second.
+func (s *Server) RequestsSince(t time.Time) int {
+	return s.requests.since(t)
+}
@@ -0,0 +1,55 @@
+package httpmock
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestCounts(t *testing.T) {
+	s := NewServer(&OKHandler{})
+	defer s.Close()
+
+	get := func(n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := http.Get(s.URL() + "/")
+				require.NoError(t, err)
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+	}
+
+	get(5)
+	mark := time.Now()
+	get(3)
+	assert.Equal(t, 8, s.TotalRequests())
+	assert.Equal(t, 3, s.RequestsSince(mark))
+	assert.Equal(t, 0, s.RequestsSince(time.Now()))
+
+	s.Reset()
+	assert.Equal(t, 0, s.TotalRequests())
+}
+
+func TestCounterWindow(t *testing.T) {
+	c := &counter{}
+	c.add()
+	// Pretend the first arrival is older than the window
+	c.base = c.base.Add(-2 * counterWindow)
+	c.add()
+	c.add()
+
+	assert.Equal(t, 3, c.count())
+	assert.Len(t, c.recent, 2, "the old arrival should have been moved out of the window")
+	assert.Equal(t, 3, c.since(c.base))
+	assert.Equal(t, 2, c.since(c.base.Add(time.Second)))
+	assert.Equal(t, 0, c.since(time.Now()))
+}
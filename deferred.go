@@ -0,0 +1,60 @@
+package httpmock
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DeferFailures makes the server collect the failures that happen on its goroutines, namely handler panics and, with
+// the mock handlers of this package, requests matching no expectation (see SoftAssertions), and report them to t
+// from the test's goroutine once it calls Close, Verify or the handler's AssertExpectations. Failures reported from
+// the server's goroutines point at net/http internals, and t.FailNow must not be called from them.
+//
+//	s := httpmock.NewServer(downstream, httpmock.DeferFailures(t))
+//	defer s.Close()
+func DeferFailures(t TestingT) Option {
+	return func(s *Server) {
+		d := &deferredFailures{t: t}
+		s.deferred = d
+		s.onPanic = d.panicked
+		SoftAssertions()(s)
+	}
+}
+
+// deferredFailures holds the failures collected with the DeferFailures option.
+type deferredFailures struct {
+	t TestingT
+
+	mu     sync.Mutex
+	panics []string
+}
+
+func (d *deferredFailures) panicked(req RequestInfo, value interface{}, stack []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.panics = append(d.panics, fmt.Sprintf("handler panicked on %s %s: %v\n%s", req.Method, req.Path, value, stack))
+}
+
+// reportDeferred reports the failures collected so far, including the handler's mismatches, forgetting them. It reports
+// whether there were none.
+func (s *Server) reportDeferred(t TestingT) bool {
+	if s.deferred == nil {
+		return true
+	}
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	s.deferred.mu.Lock()
+	panics := s.deferred.panics
+	s.deferred.panics = nil
+	s.deferred.mu.Unlock()
+
+	for _, p := range panics {
+		t.Errorf("httpmock: %s", p)
+	}
+	ok := len(panics) == 0
+	if r, isReporter := s.handler.(interface{ reportMismatches(t TestingT) bool }); isReporter {
+		ok = r.reportMismatches(t) && ok
+	}
+	return ok
+}
@@ -0,0 +1,53 @@
+package httpmock
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeferFailures(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/panic", mock.Anything).Run(func(mock.Arguments) {
+		panic("boom")
+	}).Return(Response{})
+
+	rt := &recordingT{}
+	s := NewServer(downstream, DeferFailures(rt))
+	for _, path := range []string{"/panic", "/unexpected"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+	assert.Empty(t, rt.Errors(), "failures are only reported from the test's goroutine")
+
+	s.Close()
+	errs := rt.Errors()
+	require.Len(t, errs, 2)
+	assert.True(t, strings.HasPrefix(errs[0], "httpmock: handler panicked on GET /panic: boom\n"), errs[0])
+	assert.True(t, strings.HasPrefix(errs[1], "httpmock: GET /unexpected matched no expectation: "), errs[1])
+
+	// Failures are reported once
+	assert.True(t, s.reportDeferred(rt))
+	assert.Len(t, rt.Errors(), 2)
+}
+
+func TestAssertExpectationsReportsMismatches(t *testing.T) {
+	downstream := &MockHandler{}
+	s := NewServer(downstream, SoftAssertions())
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/unexpected")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	rt := &recordingT{}
+	assert.False(t, downstream.AssertExpectations(mockTestingT{rt}))
+	require.Len(t, rt.Errors(), 1)
+	assert.Contains(t, rt.Errors()[0], "GET /unexpected matched no expectation")
+}
@@ -0,0 +1,88 @@
+package httpmock
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Schedule describes how a downstream's behavior changes over time, for soak tests of clients that adapt to it, see
+// Degrade. Its functions receive the time elapsed since the first request.
+type Schedule struct {
+	// The delay added before calling the handler (optional), e.g. GrowingLatency(0, 10*time.Millisecond)
+	Latency func(elapsed time.Duration) time.Duration
+	// The fraction of requests, from 0 to 1, answered with Error instead of calling the handler (optional), e.g.
+	// RampRate(0.5, time.Minute, 0)
+	ErrorRate func(elapsed time.Duration) float64
+	// The response to failed requests (default: an empty 503 Service Unavailable)
+	Error Response
+	// The clock telling the elapsed time (default: SystemClock); delays are real either way
+	Clock Clock
+	// The seed deciding which requests fail, so that a test sees the same sequence on every run
+	Seed int64
+}
+
+// Degrade returns a Middleware changing the server's behavior over time according to schedule, e.g. with latency
+// growing by 10ms per second and the error rate jumping to 50% after a minute:
+//
+//	s := httpmock.NewServer(downstream, httpmock.WithMiddleware(httpmock.Degrade(httpmock.Schedule{
+//		Latency:   httpmock.GrowingLatency(0, 10*time.Millisecond),
+//		ErrorRate: httpmock.RampRate(0.5, time.Minute, 0),
+//	})))
+func Degrade(schedule Schedule) Middleware {
+	if schedule.Clock == nil {
+		schedule.Clock = SystemClock
+	}
+	if schedule.Error.Status == 0 && schedule.Error.Body == nil && schedule.Error.BodyObject == nil {
+		schedule.Error.Status = http.StatusServiceUnavailable
+	}
+	var mu sync.Mutex
+	var state middlewareState
+	var start time.Time
+	random := rand.New(rand.NewSource(schedule.Seed))
+
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		now := schedule.Clock.Now()
+		mu.Lock()
+		if state.expired(req) {
+			start, random = time.Time{}, rand.New(rand.NewSource(schedule.Seed))
+		}
+		if start.IsZero() {
+			start = now
+		}
+		elapsed := now.Sub(start)
+		draw := random.Float64()
+		mu.Unlock()
+
+		if schedule.Latency != nil {
+			time.Sleep(schedule.Latency(elapsed))
+		}
+		if schedule.ErrorRate != nil && draw < schedule.ErrorRate(elapsed) {
+			return schedule.Error
+		}
+		return next(req)
+	}
+}
+
+// GrowingLatency returns a Schedule.Latency starting at base and growing by perSecond for every second elapsed.
+func GrowingLatency(base, perSecond time.Duration) func(elapsed time.Duration) time.Duration {
+	return func(elapsed time.Duration) time.Duration {
+		return base + time.Duration(float64(perSecond)*elapsed.Seconds())
+	}
+}
+
+// RampRate returns a Schedule.ErrorRate that is 0 until after, then grows linearly to rate over the duration over
+// (immediately if zero), and then stays at rate.
+func RampRate(rate float64, after, over time.Duration) func(elapsed time.Duration) float64 {
+	return func(elapsed time.Duration) float64 {
+		switch {
+		case elapsed < after:
+			return 0
+		case elapsed >= after+over:
+			return rate
+		default:
+			return rate * float64(elapsed-after) / float64(over)
+		}
+	}
+}
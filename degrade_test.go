@@ -0,0 +1,77 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegrade(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var delays []time.Duration
+	degrade := Degrade(Schedule{
+		Latency: func(elapsed time.Duration) time.Duration {
+			delays = append(delays, elapsed)
+			return 0
+		},
+		ErrorRate: RampRate(0.5, time.Minute, 0),
+		Clock:     clock,
+	})
+	next := func(req RequestInfo) Response { return Response{Body: []byte("ok")} }
+	failures := func(n int) int {
+		failed := 0
+		for i := 0; i < n; i++ {
+			resp := degrade(RequestInfo{}, next)
+			if resp.Status == http.StatusServiceUnavailable {
+				failed++
+			}
+		}
+		return failed
+	}
+
+	assert.Equal(t, 0, failures(100))
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 0, failures(100))
+	assert.Equal(t, 30*time.Second, delays[len(delays)-1])
+	clock.Advance(30 * time.Second)
+	assert.InDelta(t, 500, failures(1000), 60)
+}
+
+func TestScheduleFunctions(t *testing.T) {
+	latency := GrowingLatency(5*time.Millisecond, 10*time.Millisecond)
+	assert.Equal(t, 5*time.Millisecond, latency(0))
+	assert.Equal(t, 20*time.Millisecond, latency(1500*time.Millisecond))
+
+	rate := RampRate(0.5, time.Minute, 10*time.Second)
+	assert.Equal(t, 0.0, rate(59*time.Second))
+	assert.Equal(t, 0.25, rate(65*time.Second))
+	assert.Equal(t, 0.5, rate(time.Hour))
+}
+
+func TestDegradeServerReset(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	downstream := NewMockHandler(t)
+	s := NewServer(downstream, WithMiddleware(Degrade(Schedule{ErrorRate: RampRate(1, time.Minute, 0), Clock: clock})))
+	defer s.Close()
+
+	get := func() int {
+		resp, err := http.Get(s.URL() + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{Status: 200})
+	assert.Equal(t, 200, get())
+	clock.Advance(time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, get())
+
+	s.Reset()
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{Status: 200})
+	assert.Equal(t, 200, get(), "the schedule should start over after Reset")
+	clock.Advance(time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, get())
+}
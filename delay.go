@@ -0,0 +1,115 @@
+package httpmock
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Distribution draws response delays, for simulating realistic latency profiles with the Latency middleware. The
+// distributions of this package are seeded, so a test sees the same sequence of delays on every run, and are safe
+// for concurrent use.
+type Distribution interface {
+	Sample() time.Duration
+}
+
+// Latency returns a Middleware delaying every response by a delay drawn from dist, before the handler is called.
+//
+//	s := httpmock.NewServer(downstream, httpmock.WithMiddleware(
+//		httpmock.Latency(httpmock.LogNormalDelay(20*time.Millisecond, 0.5, 1))))
+func Latency(dist Distribution) Middleware {
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		time.Sleep(dist.Sample())
+		return next(req)
+	}
+}
+
+// FixedDelay returns a Distribution always drawing d.
+func FixedDelay(d time.Duration) Distribution {
+	return fixedDelay(d)
+}
+
+type fixedDelay time.Duration
+
+func (d fixedDelay) Sample() time.Duration {
+	return time.Duration(d)
+}
+
+// UniformDelay returns a Distribution drawing delays uniformly between min and max.
+func UniformDelay(min, max time.Duration, seed int64) Distribution {
+	return newSeededDelay(seed, func(r *rand.Rand) float64 {
+		return float64(min) + r.Float64()*float64(max-min)
+	})
+}
+
+// NormalDelay returns a Distribution drawing delays from a normal distribution with the given mean and standard
+// deviation. Negative draws are clamped to zero.
+func NormalDelay(mean, stddev time.Duration, seed int64) Distribution {
+	return newSeededDelay(seed, func(r *rand.Rand) float64 {
+		return float64(mean) + r.NormFloat64()*float64(stddev)
+	})
+}
+
+// LogNormalDelay returns a Distribution drawing delays from a log-normal distribution with the given median and shape
+// sigma (the standard deviation of the delay's logarithm). Its long right tail resembles real service latencies:
+// with a sigma of 0.5, the 99th percentile is about 3.2 times the median.
+func LogNormalDelay(median time.Duration, sigma float64, seed int64) Distribution {
+	return newSeededDelay(seed, func(r *rand.Rand) float64 {
+		return float64(median) * math.Exp(r.NormFloat64()*sigma)
+	})
+}
+
+// RampDelay returns a Distribution whose delays grow linearly from "from" to "to" over the duration "over", starting
+// at its first Sample, and then stay at "to", as told by clock (SystemClock if nil). It models a downstream degrading
+// under sustained load.
+func RampDelay(from, to, over time.Duration, clock Clock) Distribution {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &rampDelay{from: from, to: to, over: over, clock: clock}
+}
+
+type rampDelay struct {
+	from, to, over time.Duration
+	clock          Clock
+
+	mu    sync.Mutex
+	start time.Time
+}
+
+func (d *rampDelay) Sample() time.Duration {
+	now := d.clock.Now()
+	d.mu.Lock()
+	if d.start.IsZero() {
+		d.start = now
+	}
+	elapsed := now.Sub(d.start)
+	d.mu.Unlock()
+	if elapsed >= d.over {
+		return d.to
+	}
+	return d.from + time.Duration(float64(d.to-d.from)*float64(elapsed)/float64(d.over))
+}
+
+// seededDelay is a Distribution drawing from a seeded source, which it guards since rand.Rand isn't safe for
+// concurrent use.
+type seededDelay struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+	draw func(r *rand.Rand) float64
+}
+
+func newSeededDelay(seed int64, draw func(r *rand.Rand) float64) *seededDelay {
+	return &seededDelay{rand: rand.New(rand.NewSource(seed)), draw: draw}
+}
+
+func (d *seededDelay) Sample() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	v := d.draw(d.rand)
+	if v < 0 {
+		return 0
+	}
+	return time.Duration(v)
+}
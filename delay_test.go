@@ -0,0 +1,63 @@
+package httpmock
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistributions(t *testing.T) {
+	sample := func(d Distribution) []time.Duration {
+		samples := make([]time.Duration, 1000)
+		for i := range samples {
+			samples[i] = d.Sample()
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		return samples
+	}
+
+	uniform := sample(UniformDelay(10*time.Millisecond, 20*time.Millisecond, 1))
+	assert.GreaterOrEqual(t, uniform[0], 10*time.Millisecond)
+	assert.Less(t, uniform[999], 20*time.Millisecond)
+
+	normal := sample(NormalDelay(10*time.Millisecond, 20*time.Millisecond, 1))
+	assert.Equal(t, time.Duration(0), normal[0], "negative draws are clamped")
+	assert.InDelta(t, 10*time.Millisecond, normal[500], float64(2*time.Millisecond))
+
+	logNormal := sample(LogNormalDelay(10*time.Millisecond, 0.5, 1))
+	assert.InDelta(t, 10*time.Millisecond, logNormal[500], float64(time.Millisecond))
+	assert.InDelta(t, 32*time.Millisecond, logNormal[990], float64(5*time.Millisecond))
+
+	assert.Equal(t, logNormal, sample(LogNormalDelay(10*time.Millisecond, 0.5, 1)), "same seed, same delays")
+}
+
+func TestLatency(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{})
+
+	s := NewServer(downstream, WithMiddleware(Latency(FixedDelay(50*time.Millisecond))))
+	defer s.Close()
+
+	start := time.Now()
+	resp, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	downstream.AssertExpectations(t)
+}
+
+func TestRampDelay(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	d := RampDelay(10*time.Millisecond, 110*time.Millisecond, time.Minute, clock)
+	assert.Equal(t, 10*time.Millisecond, d.Sample())
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 60*time.Millisecond, d.Sample())
+	clock.Advance(time.Hour)
+	assert.Equal(t, 110*time.Millisecond, d.Sample())
+}
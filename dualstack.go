@@ -0,0 +1,129 @@
+package httpmock
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// DualStack makes the server listen on both the IPv4 and the IPv6 loopback addresses, on the same port, for testing
+// dual-stack dialing in clients such as Happy Eyeballs. URL is the IPv4 one and IPv6URL the IPv6 one, while a URL
+// with the host "localhost" lets the client pick. RequestInfo.AddressFamily tells which one each request used. It
+// panics if the system has no IPv6 loopback, or no port is free on both.
+func DualStack() Option {
+	return func(s *Server) {
+		l, err := listenDualStack()
+		if err != nil {
+			panic("httpmock: " + err.Error())
+		}
+		s.listener = l
+	}
+}
+
+// AddressFamily returns "IPv4" or "IPv6" depending on the server address that the request was received on, or "" if
+// it is unknown.
+func (r RequestInfo) AddressFamily() string {
+	host, _, err := net.SplitHostPort(r.LocalAddr)
+	if err != nil {
+		return ""
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return "IPv4"
+	default:
+		return "IPv6"
+	}
+}
+
+// IPv6URL returns the URL of a server listening on the IPv6 loopback address, such as with the DualStack option.
+func (s *Server) IPv6URL() string {
+	u, err := url.Parse(s.URL())
+	if err != nil {
+		return ""
+	}
+	u.Host = net.JoinHostPort("::1", u.Port())
+	return u.String()
+}
+
+// listenDualStack listens on the same port on both loopback addresses, retrying with another port if the one picked
+// for IPv4 is taken for IPv6.
+func listenDualStack() (net.Listener, error) {
+	var lastErr error
+	for attempt := 0; attempt < 10; attempt++ {
+		l4, err := net.Listen("tcp4", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		port := l4.Addr().(*net.TCPAddr).Port
+		l6, err := net.Listen("tcp6", net.JoinHostPort("::1", strconv.Itoa(port)))
+		if err != nil {
+			l4.Close()
+			lastErr = err
+			continue
+		}
+		return newMultiListener(l4, l6), nil
+	}
+	return nil, fmt.Errorf("failed to listen on both loopback addresses: %v", lastErr)
+}
+
+// multiListener accepts the connections of several listeners. Its address is the one of the first listener.
+type multiListener struct {
+	listeners []net.Listener
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newMultiListener(listeners ...net.Listener) *multiListener {
+	l := &multiListener{listeners: listeners, conns: make(chan net.Conn), done: make(chan struct{})}
+	for _, sub := range listeners {
+		go l.acceptFrom(sub)
+	}
+	return l
+}
+
+func (l *multiListener) acceptFrom(sub net.Listener) {
+	for {
+		c, err := sub.Accept()
+		if err != nil {
+			return
+		}
+		select {
+		case l.conns <- c:
+		case <-l.done:
+			c.Close()
+			return
+		}
+	}
+}
+
+func (l *multiListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *multiListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		for _, sub := range l.listeners {
+			if closeErr := sub.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	})
+	return err
+}
+
+func (l *multiListener) Addr() net.Addr {
+	return l.listeners[0].Addr()
+}
@@ -0,0 +1,33 @@
+package httpmock
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDualStack(t *testing.T) {
+	if l, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Skip("no IPv6 loopback:", err)
+	} else {
+		l.Close()
+	}
+
+	s := NewServer(&OKHandler{}, DualStack(), RecordHistory())
+	defer s.Close()
+
+	for _, u := range []string{s.URL(), s.IPv6URL()} {
+		resp, err := http.Get(u + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	history := s.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, "IPv4", history[0].AddressFamily())
+	assert.Equal(t, "IPv6", history[1].AddressFamily())
+	assert.Equal(t, "", RequestInfo{}.AddressFamily())
+}
@@ -0,0 +1,95 @@
+package httpmock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ETag returns a strong entity tag for body, e.g. `"2cf24dba5fb0a30e"`, derived from its SHA-256 digest.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// WeakETag returns a weak entity tag for body, e.g. `W/"2cf24dba5fb0a30e"`.
+func WeakETag(body []byte) string {
+	return "W/" + ETag(body)
+}
+
+// WithETag returns resp with an ETag header holding the strong entity tag of its body. A BodyObject is marshaled to
+// JSON first, and computed responses are tagged when they are served.
+func WithETag(resp Response) Response {
+	if resp.compute != nil {
+		compute := resp.compute
+		resp.compute = func(req RequestInfo) Response { return WithETag(compute(req)) }
+		return resp
+	}
+	resp = renderBodyObject(resp)
+	resp.Header = cloneHeader(resp.Header)
+	resp.Header.Set("ETag", ETag(resp.Body))
+	return resp
+}
+
+// IfMatchMatcher returns a mock.MatchedBy func to check if the headers argument has an If-Match header that is "*" or
+// lists etag, using the strong comparison (weak tags never match), as for a PUT guarded by optimistic concurrency.
+// Requests without the header don't match.
+//
+//	downstream.On("HandleWithHeaders", "PUT", "/doc", httpmock.IfMatchMatcher(etag), mock.Anything).Return(
+//		httpmock.Response{Status: 204})
+//	downstream.On("HandleWithHeaders", "PUT", "/doc", mock.Anything, mock.Anything).Return(
+//		httpmock.Response{Status: http.StatusPreconditionFailed})
+func IfMatchMatcher(etag string) interface{} {
+	return mock.MatchedBy(func(headers http.Header) bool {
+		for _, tag := range etagList(headers.Values("If-Match")) {
+			if tag == "*" || (tag == etag && !strings.HasPrefix(tag, "W/")) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// IfNoneMatchMatcher returns a mock.MatchedBy func to check if the headers argument has an If-None-Match header that is
+// "*" or lists etag, using the weak comparison, as for a conditional GET that should get a 304. Requests without the
+// header don't match.
+func IfNoneMatchMatcher(etag string) interface{} {
+	etag = strings.TrimPrefix(etag, "W/")
+	return mock.MatchedBy(func(headers http.Header) bool {
+		for _, tag := range etagList(headers.Values("If-None-Match")) {
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// etagList splits the values of an If-Match or If-None-Match header into entity tags, which may contain commas
+// inside their quotes.
+func etagList(values []string) []string {
+	var tags []string
+	for _, v := range values {
+		for {
+			v = strings.TrimLeft(v, " \t,")
+			if v == "" {
+				break
+			}
+			end := strings.IndexByte(v, ',')
+			if start := strings.IndexByte(v, '"'); start >= 0 && (end < 0 || start < end) {
+				if closing := strings.IndexByte(v[start+1:], '"'); closing >= 0 {
+					end = start + 1 + closing + 1
+				}
+			}
+			if end < 0 {
+				end = len(v)
+			}
+			tags = append(tags, strings.TrimSpace(v[:end]))
+			v = v[end:]
+		}
+	}
+	return tags
+}
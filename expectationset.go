@@ -0,0 +1,117 @@
+package httpmock
+
+import (
+	"fmt"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ExpectationSet is a reusable bundle of expectations, such as "healthy auth service", which can be applied to any
+// mock handler or Server. It lets a large test codebase share fixture libraries instead of repeating stubs.
+//
+//	var HealthyAuth = httpmock.NewExpectationSet().
+//		On("Handle", "GET", "/health", mock.Anything).Return(httpmock.Response{Status: 200}).Maybe().
+//		On("Handle", "POST", "/token", mock.Anything).Return(httpmock.Response{BodyObject: token}).Set()
+//
+//	HealthyAuth.ApplyTo(downstream)
+type ExpectationSet struct {
+	expectations []*Expectation
+}
+
+// Expectation is a single expectation of an ExpectationSet, mirroring the most common methods of mock.Call.
+type Expectation struct {
+	set        *ExpectationSet
+	method     string
+	args       []interface{}
+	returns    []interface{}
+	times      int
+	optional   bool
+	hasReturns bool
+}
+
+// Mocker is implemented by the mock handlers of this package, by way of their embedded mock.Mock.
+type Mocker interface {
+	On(methodName string, arguments ...interface{}) *mock.Call
+}
+
+// NewExpectationSet returns an empty ExpectationSet.
+func NewExpectationSet() *ExpectationSet {
+	return &ExpectationSet{}
+}
+
+// On adds an expectation for the given handler method and arguments to the set, like mock.Mock.On.
+func (s *ExpectationSet) On(method string, args ...interface{}) *Expectation {
+	e := &Expectation{set: s, method: method, args: args}
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// Include adds all the expectations of the other sets to this one, so sets can be composed.
+func (s *ExpectationSet) Include(others ...*ExpectationSet) *ExpectationSet {
+	for _, o := range others {
+		s.expectations = append(s.expectations, o.expectations...)
+	}
+	return s
+}
+
+// ApplyTo registers every expectation of the set on m, in the order they were added.
+func (s *ExpectationSet) ApplyTo(m Mocker) {
+	for _, e := range s.expectations {
+		call := m.On(e.method, e.args...)
+		if e.hasReturns {
+			call = call.Return(e.returns...)
+		}
+		if e.times > 0 {
+			call = call.Times(e.times)
+		}
+		if e.optional {
+			call.Maybe()
+		}
+	}
+}
+
+// Apply registers the expectations of the given sets on the server's handler, which must be a Mocker such as a
+// MockHandler.
+func (s *Server) Apply(sets ...*ExpectationSet) {
+	m, ok := s.handler.(Mocker)
+	if !ok {
+		panic(fmt.Sprintf("httpmock: can't apply expectations to handler of type %T", s.handler))
+	}
+	for _, set := range sets {
+		set.ApplyTo(m)
+	}
+}
+
+// Return sets the response of the expectation, like mock.Call.Return.
+func (e *Expectation) Return(resp Response) *Expectation {
+	e.returns = []interface{}{resp}
+	e.hasReturns = true
+	return e
+}
+
+// Once makes the expectation match only once, like mock.Call.Once.
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Times makes the expectation match only n times, like mock.Call.Times.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Maybe makes the expectation optional for AssertExpectations, like mock.Call.Maybe.
+func (e *Expectation) Maybe() *Expectation {
+	e.optional = true
+	return e
+}
+
+// On adds another expectation to the set the expectation belongs to, for chaining.
+func (e *Expectation) On(method string, args ...interface{}) *Expectation {
+	return e.set.On(method, args...)
+}
+
+// Set returns the set the expectation belongs to, ending a chain.
+func (e *Expectation) Set() *ExpectationSet {
+	return e.set
+}
@@ -0,0 +1,38 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectationSet(t *testing.T) {
+	healthy := NewExpectationSet().
+		On("Handle", "GET", "/health", mock.Anything).Return(Response{Status: 204}).Maybe().Set()
+	auth := NewExpectationSet().
+		On("Handle", "POST", "/token", mock.Anything).Return(Response{Status: 201}).Once().
+		On("Handle", "POST", "/token", mock.Anything).Return(Response{Status: 429}).Set().
+		Include(healthy)
+
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			downstream := NewMockHandler(t)
+			s := NewServer(downstream)
+			defer s.Close()
+			s.Apply(auth)
+
+			for _, status := range []int{201, 429} {
+				resp, err := http.Post(s.URL()+"/token", "", nil)
+				require.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, status, resp.StatusCode)
+			}
+			downstream.AssertExpectations(t)
+		})
+	}
+
+	assert.Panics(t, func() { NewUnstartedServer(&OKHandler{}).Apply(healthy) })
+}
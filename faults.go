@@ -0,0 +1,98 @@
+package httpmock
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// FailWriteAt returns a Response that starts out as resp, declaring the full Content-Length, but whose connection is
+// closed after offset bytes of the body have been written, for testing how clients handle downloads failing partway.
+func FailWriteAt(resp Response, offset int) Response {
+	resp = renderBodyObject(resp)
+	if offset > len(resp.Body) {
+		offset = len(resp.Body)
+	}
+	return Response{Hijack: func(conn net.Conn, rw *bufio.ReadWriter) {
+		header := cloneHeader(resp.Header)
+		header.Set("Content-Length", strconv.Itoa(len(resp.Body)))
+		writeHead(rw, resp.Status, header)
+		rw.Write(resp.Body[:offset])
+	}}
+}
+
+// readFault closes the connection of matching requests after offset bytes of their body, see FailReadAt.
+type readFault struct {
+	method, path string
+	offset       int64
+}
+
+// FailReadAt makes the server close the connection of requests for method (any if empty) and path (ignoring the query
+// string) once it has read offset bytes of their body, without calling the Handler, for testing how clients handle
+// uploads failing partway. Clients typically see a broken pipe or connection reset while sending, or an EOF while
+// waiting for the response if they had sent the whole body already.
+func FailReadAt(method, path string, offset int64) Option {
+	return func(s *Server) {
+		s.readFaults = append(s.readFaults, readFault{method: method, path: path, offset: offset})
+	}
+}
+
+// failRead closes the connection of r after reading the body up to the offset of the first matching fault, reporting
+// whether there was one.
+func (s *Server) failRead(w http.ResponseWriter, r *http.Request) bool {
+	for _, f := range s.readFaults {
+		if (f.method != "" && f.method != r.Method) || f.path != r.URL.Path {
+			continue
+		}
+		_, _ = io.CopyN(io.Discard, r.Body, f.offset)
+		// Closing the connection with unread data makes most systems reset it
+		if err := hijack(w, func(net.Conn, *bufio.ReadWriter) {}); err != nil {
+			s.reportError("failed to hijack connection for %s %s: %v", r.Method, r.URL, err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return true
+	}
+	return false
+}
+
+// ManyHeadersResponse returns resp with count more headers, X-Filler-1 to X-Filler-<count>, for testing that clients
+// limit the number of response headers they read.
+func ManyHeadersResponse(resp Response, count int) Response {
+	resp.Header = cloneHeader(resp.Header)
+	for i := 1; i <= count; i++ {
+		resp.Header.Set("X-Filler-"+strconv.Itoa(i), "filler")
+	}
+	return resp
+}
+
+// OversizedHeader returns a Middleware setting the header name to a value of size bytes on every response, see
+// OversizedHeaderResponse. Combine it with OnPaths to only affect some routes.
+func OversizedHeader(name string, size int) Middleware {
+	return RewriteResponse(func(req RequestInfo, resp Response) Response {
+		return OversizedHeaderResponse(resp, name, size)
+	})
+}
+
+// ManyHeaders returns a Middleware adding count headers to every response, see ManyHeadersResponse. Combine it with
+// OnPaths to only affect some routes.
+func ManyHeaders(count int) Middleware {
+	return RewriteResponse(func(req RequestInfo, resp Response) Response {
+		return ManyHeadersResponse(resp, count)
+	})
+}
+
+// WithOversizedHeader makes the server set the header name to a value of size bytes on every response, for testing
+// client protections like http.Transport.MaxResponseHeaderBytes. See OversizedHeader.
+//
+//	s := httpmock.NewServer(downstream, httpmock.WithOversizedHeader("Set-Cookie", 1<<20))
+func WithOversizedHeader(name string, size int) Option {
+	return WithMiddleware(OversizedHeader(name, size))
+}
+
+// WithManyHeaders makes the server add count headers to every response, e.g. thousands, for testing that clients
+// limit the number of headers they read. See ManyHeaders.
+func WithManyHeaders(count int) Option {
+	return WithMiddleware(ManyHeaders(count))
+}
@@ -0,0 +1,64 @@
+package httpmock
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailWriteAt(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/download", mock.Anything).Return(
+		FailWriteAt(Response{Body: []byte("0123456789")}, 4))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/download")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, int64(10), resp.ContentLength)
+	body, err := io.ReadAll(resp.Body)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Equal(t, "0123", string(body))
+}
+
+func TestFailReadAt(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "POST", "/ok", mock.Anything).Return(Response{})
+	s := NewServer(downstream, FailReadAt("POST", "/upload", 1024))
+	defer s.Close()
+
+	resp, err := http.Post(s.URL()+"/upload", "application/octet-stream", bytes.NewReader(make([]byte, 1<<20)))
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.Error(t, err)
+
+	resp, err = http.Post(s.URL()+"/ok", "application/octet-stream", bytes.NewReader(make([]byte, 1<<20)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	downstream.AssertNotCalled(t, "Handle", "POST", "/upload", mock.Anything)
+}
+
+func TestHeaderFaults(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{Body: []byte("ok")})
+	s := NewServer(downstream, WithManyHeaders(2000), WithOversizedHeader("X-Big", 8<<10))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "filler", resp.Header.Get("X-Filler-2000"))
+	assert.Len(t, resp.Header.Get("X-Big"), 8<<10)
+	assert.Greater(t, len(resp.Header), 2000)
+
+	client := &http.Client{Transport: &http.Transport{MaxResponseHeaderBytes: 16 << 10}}
+	_, err = client.Get(s.URL() + "/")
+	assert.ErrorContains(t, err, "header")
+}
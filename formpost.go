@@ -0,0 +1,73 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+var formPostTemplate = template.Must(template.New("formpost").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Redirecting</title></head>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.Action}}">
+{{- range .Fields}}
+<input type="hidden" name="{{.Name}}" value="{{.Value}}">
+{{- end}}
+<noscript><button type="submit">Continue</button></noscript>
+</form>
+</body>
+</html>
+`))
+
+// FormPostResponse returns a Response with an HTML page whose form POSTs the given fields to action as soon as it is
+// loaded, as used by SAML's HTTP-POST binding and OAuth's form_post response mode, for testing clients that must parse
+// and follow such pages. Fields are in key order, and the page tells caches not to store it.
+func FormPostResponse(action string, fields url.Values) Response {
+	type field struct{ Name, Value string }
+	var data struct {
+		Action string
+		Fields []field
+	}
+	data.Action = action
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range fields[k] {
+			data.Fields = append(data.Fields, field{Name: k, Value: v})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := formPostTemplate.Execute(&buf, data); err != nil {
+		panic("httpmock: failed to render form post page: " + err.Error())
+	}
+	return Response{
+		Header: http.Header{
+			"Content-Type":  {"text/html; charset=utf-8"},
+			"Cache-Control": {"no-cache, no-store"},
+			"Pragma":        {"no-cache"},
+		},
+		Body: buf.Bytes(),
+	}
+}
+
+// SAMLPostResponse returns a FormPostResponse posting message, e.g. a SAML response XML document, base64-encoded as
+// the SAMLResponse field to action, along with relayState as the RelayState field unless it is empty, per the SAML
+// HTTP-POST binding. For a SAML request, use FormPostResponse with a SAMLRequest field.
+//
+//	downstream.On("Handle", "GET", "/sso", mock.Anything).Return(httpmock.SAMLPostResponse(
+//		app.URL+"/saml/acs", []byte(assertion), "/dashboard"))
+func SAMLPostResponse(action string, message []byte, relayState string) Response {
+	fields := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(message)}}
+	if relayState != "" {
+		fields.Set("RelayState", relayState)
+	}
+	return FormPostResponse(action, fields)
+}
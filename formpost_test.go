@@ -0,0 +1,42 @@
+package httpmock
+
+import (
+	"encoding/base64"
+	"html"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormPostResponse(t *testing.T) {
+	resp := FormPostResponse(`https://app.example.com/cb?a=1&b="2"`, map[string][]string{
+		"state": {"x<y"},
+		"code":  {"abc"},
+	})
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache, no-store", resp.Header.Get("Cache-Control"))
+	body := string(resp.Body)
+	assert.Contains(t, body, `<form method="post" action="https://app.example.com/cb?a=1&amp;b=%222%22">`)
+	assert.Contains(t, body, `<input type="hidden" name="code" value="abc">`+"\n"+
+		`<input type="hidden" name="state" value="x&lt;y">`)
+	assert.Contains(t, body, `onload="document.forms[0].submit()"`)
+}
+
+func TestSAMLPostResponse(t *testing.T) {
+	message := []byte(`<samlp:Response ID="_1"></samlp:Response>`)
+	resp := SAMLPostResponse("https://sp.example.com/acs", message, "/dashboard")
+
+	fields := map[string]string{}
+	for _, m := range regexp.MustCompile(`name="(\w+)" value="([^"]*)"`).FindAllStringSubmatch(string(resp.Body), -1) {
+		fields[m[1]] = html.UnescapeString(m[2])
+	}
+	assert.Equal(t, "/dashboard", fields["RelayState"])
+	decoded, err := base64.StdEncoding.DecodeString(fields["SAMLResponse"])
+	require.NoError(t, err)
+	assert.Equal(t, message, decoded)
+
+	resp = SAMLPostResponse("https://sp.example.com/acs", message, "")
+	assert.NotContains(t, string(resp.Body), "RelayState")
+}
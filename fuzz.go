@@ -0,0 +1,110 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ResponseMutation corrupts a response for fuzzing client-side parsing and error handling, drawing any randomness it
+// needs from r. The response's BodyObject, if any, has already been marshaled into its Body.
+type ResponseMutation func(r *rand.Rand, resp Response) Response
+
+// FuzzResponses returns a Middleware mutating responses with the given mutations (all of the ones of this package if
+// none are given), starting from the known-good responses of the handler. Each response is mutated with probability
+// rate, by one mutation picked at random. The choices are drawn from a source seeded with seed, so a failure can be
+// reproduced by running the same requests against the same seed.
+//
+//	s := httpmock.NewServer(downstream, httpmock.WithMiddleware(httpmock.FuzzResponses(42, 0.5)))
+func FuzzResponses(seed int64, rate float64, mutations ...ResponseMutation) Middleware {
+	if len(mutations) == 0 {
+		mutations = []ResponseMutation{TruncateBody(), CorruptHeader(), WrongContentLength()}
+	}
+	var mu sync.Mutex
+	var state middlewareState
+	r := rand.New(rand.NewSource(seed))
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		resp := next(req)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if state.expired(req) {
+			r = rand.New(rand.NewSource(seed))
+		}
+		if r.Float64() >= rate {
+			return resp
+		}
+		mutation := mutations[r.Intn(len(mutations))]
+		return mutation(r, renderBodyObject(resp))
+	}
+}
+
+// TruncateBody returns a ResponseMutation cutting the body short at a random point, e.g. leaving JSON unterminated.
+func TruncateBody() ResponseMutation {
+	return func(r *rand.Rand, resp Response) Response {
+		if len(resp.Body) > 0 {
+			resp.Body = resp.Body[:r.Intn(len(resp.Body))]
+		}
+		return resp
+	}
+}
+
+// CorruptHeader returns a ResponseMutation replacing the value of one of the response's headers, picked at random,
+// with random bytes. Responses without headers get a corrupted Content-Type.
+func CorruptHeader() ResponseMutation {
+	return func(r *rand.Rand, resp Response) Response {
+		resp.Header = cloneHeader(resp.Header)
+		keys := make([]string, 0, len(resp.Header))
+		for k := range resp.Header {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		key := "Content-Type"
+		if len(keys) > 0 {
+			key = keys[r.Intn(len(keys))]
+		}
+
+		garbage := make([]byte, 1+r.Intn(32))
+		for i := range garbage {
+			// Printable and high bytes, since the server replaces newlines
+			garbage[i] = byte(0x20 + r.Intn(0xe0))
+		}
+		resp.Header.Set(key, string(garbage))
+		return resp
+	}
+}
+
+// WrongContentLength returns a ResponseMutation declaring a Content-Length that doesn't match the body: longer, so the
+// connection is closed before the client has read the declared length, or shorter, so the body is cut short.
+func WrongContentLength() ResponseMutation {
+	return func(r *rand.Rand, resp Response) Response {
+		resp.Header = cloneHeader(resp.Header)
+		length := len(resp.Body) + 1 + r.Intn(64)
+		if len(resp.Body) > 0 && r.Intn(2) == 0 {
+			length = r.Intn(len(resp.Body))
+		}
+		resp.Header.Set("Content-Length", strconv.Itoa(length))
+		return resp
+	}
+}
+
+// renderBodyObject marshals the BodyObject of resp into its Body, like the server does when writing it. Marshaling
+// errors are left for the server to report.
+func renderBodyObject(resp Response) Response {
+	if resp.BodyObject == nil {
+		return resp
+	}
+	data, err := json.Marshal(resp.BodyObject)
+	if err != nil {
+		return resp
+	}
+	resp.Header = cloneHeader(resp.Header)
+	if resp.Header.Get("Content-Type") == "" {
+		resp.Header.Set("Content-Type", "application/json")
+	}
+	resp.Body = data
+	resp.BodyObject = nil
+	return resp
+}
@@ -0,0 +1,56 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseMutations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	resp := Response{Header: http.Header{"X-Test": {"ok"}}, Body: []byte(`{"status": "ok"}`)}
+
+	truncated := TruncateBody()(r, resp)
+	assert.Less(t, len(truncated.Body), len(resp.Body))
+	assert.Error(t, json.Unmarshal(truncated.Body, new(interface{})))
+
+	corrupted := CorruptHeader()(r, resp)
+	assert.NotEqual(t, "ok", corrupted.Header.Get("X-Test"))
+	assert.Equal(t, "ok", resp.Header.Get("X-Test"), "the original response is left alone")
+
+	wrong := WrongContentLength()(r, resp)
+	assert.NotEqual(t, "16", wrong.Header.Get("Content-Length"))
+}
+
+func TestFuzzResponses(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{BodyObject: map[string]string{"status": "ok"}})
+
+	bodies := func(seed int64) []string {
+		s := NewServer(downstream, WithMiddleware(FuzzResponses(seed, 1, TruncateBody())))
+		defer s.Close()
+		var bodies []string
+		for i := 0; i < 5; i++ {
+			resp, err := http.Get(s.URL() + "/")
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			bodies = append(bodies, string(body))
+		}
+		return bodies
+	}
+
+	first := bodies(7)
+	for _, body := range first {
+		assert.Less(t, len(body), len(`{"status":"ok"}`))
+	}
+	assert.Equal(t, first, bodies(7))
+}
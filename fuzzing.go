@@ -0,0 +1,95 @@
+package httpmock
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// These helpers drive handlers and servers without network I/O, from the inputs of Go's native fuzzing:
+//
+//	func FuzzClientParsing(f *testing.F) {
+//		f.Add([]byte("\x01/orders\nContent-Type: application/json\n\n{\"id\": 1}"))
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			resp := httpmock.ServeRequest(handler, httpmock.RequestFromBytes(data))
+//			// ... feed resp to the client code under test
+//		})
+//	}
+
+var fuzzMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead,
+	http.MethodOptions,
+}
+
+// RequestFromBytes deterministically synthesizes a request from arbitrary fuzz input. The first byte selects the
+// method, the rest of the first line is the path (with a leading slash added if missing), the following lines up to
+// an empty one are "Key: value" headers, and the remainder is the body. Any input gives a request, so that the fuzzer
+// can explore freely, while readable seed corpora can still be written by hand.
+func RequestFromBytes(data []byte) RequestInfo {
+	req := RequestInfo{Method: http.MethodGet, Path: "/", Header: http.Header{}, Proto: "HTTP/1.1"}
+	if len(data) == 0 {
+		return req
+	}
+	req.Method = fuzzMethods[int(data[0])%len(fuzzMethods)]
+	rest := data[1:]
+
+	line, rest, _ := bytes.Cut(rest, []byte("\n"))
+	if !bytes.HasPrefix(line, []byte("/")) {
+		req.Path = "/"
+	} else {
+		req.Path = ""
+	}
+	req.Path += string(line)
+
+	for len(rest) > 0 {
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		if len(bytes.TrimSpace(line)) == 0 {
+			break
+		}
+		key, value, _ := strings.Cut(string(line), ":")
+		req.Header.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	req.Body = rest
+	return req
+}
+
+// ServeRequest passes req to handler like a Server would, calling HandleRequest, HandleWithHeaders or Handle
+// depending on the interfaces it implements, and returns its response.
+func ServeRequest(handler Handler, req RequestInfo) Response {
+	var resp Response
+	switch h := handler.(type) {
+	case HandlerWithRequest:
+		resp = h.HandleRequest(req)
+	case HandlerWithHeaders:
+		resp = h.HandleWithHeaders(req.Method, req.Path, req.Header, req.Body)
+	default:
+		resp = h.Handle(req.Method, req.Path, req.Body)
+	}
+	if resp.compute != nil {
+		resp = resp.compute(req)
+	}
+	return resp
+}
+
+// ServeRaw parses raw as an HTTP/1.x request and serves it through the whole server, including its middleware and
+// other options, without any network I/O, so the mock's own request parsing can be fuzzed. It returns an error if
+// raw isn't a valid request. The server doesn't need to be started.
+func (s *Server) ServeRaw(raw []byte) (*http.Response, error) {
+	r, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, err
+	}
+	// Read the body up front, as a real server would have
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	w := httptest.NewRecorder()
+	s.HTTPHandler().ServeHTTP(w, r)
+	return w.Result(), nil
+}
@@ -0,0 +1,48 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestFromBytes(t *testing.T) {
+	req := RequestFromBytes([]byte("\x01orders?x=1\nContent-Type: application/json\n\n{\"id\": 1}"))
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "/orders?x=1", req.Path)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, `{"id": 1}`, string(req.Body))
+
+	assert.Equal(t, "GET", RequestFromBytes(nil).Method)
+}
+
+func TestServeRequest(t *testing.T) {
+	downstream := NewMockHandlerWithHeaders(t)
+	downstream.On("HandleWithHeaders", "POST", "/orders", HeaderMatcher("Content-Type", "application/json"), []byte(`{}`)).
+		Return(Response{Status: 201})
+
+	resp := ServeRequest(downstream, RequestFromBytes([]byte("\x01/orders\nContent-Type: application/json\n\n{}")))
+	assert.Equal(t, 201, resp.Status)
+
+	downstream.AssertExpectations(t)
+}
+
+func FuzzServeRaw(f *testing.F) {
+	f.Add([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	f.Add([]byte("POST /orders HTTP/1.1\r\nHost: example.com\r\nContent-Length: 2\r\n\r\n{}"))
+
+	downstream := &MockHandler{}
+	downstream.On("Handle", AnyMethod, mock.Anything, mock.Anything).Return(Response{Status: 204})
+	s := NewUnstartedServer(downstream)
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		resp, err := s.ServeRaw(raw)
+		if err != nil {
+			return
+		}
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
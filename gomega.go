@@ -0,0 +1,107 @@
+package httpmock
+
+import (
+	"fmt"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// GomegaMatcher is a matcher for Gomega's Expect(...).To(...), implementing its types.GomegaMatcher interface without
+// this package depending on Gomega.
+//
+//	Expect(s).To(httpmock.HaveReceived("POST", "/orders"))
+//	Expect(s).To(httpmock.HaveReceivedTimes("GET", httpmock.PathPrefixMatcher("/orders/"), 2))
+//	Expect(body).To(httpmock.MatchArgument(httpmock.JSONMatcher(order)))
+type GomegaMatcher struct {
+	match   func(actual interface{}) (bool, error)
+	message func(actual interface{}, negated bool) string
+}
+
+// Match makes this implement Gomega's types.GomegaMatcher interface.
+func (m GomegaMatcher) Match(actual interface{}) (bool, error) {
+	return m.match(actual)
+}
+
+// FailureMessage makes this implement Gomega's types.GomegaMatcher interface.
+func (m GomegaMatcher) FailureMessage(actual interface{}) string {
+	return m.message(actual, false)
+}
+
+// NegatedFailureMessage makes this implement Gomega's types.GomegaMatcher interface.
+func (m GomegaMatcher) NegatedFailureMessage(actual interface{}) string {
+	return m.message(actual, true)
+}
+
+// HaveReceived returns a GomegaMatcher checking that a *Server with the RecordHistory option has received at least one
+// request for method and path. Both can be plain values or argument matchers such as PathGlobMatcher or
+// mock.Anything.
+func HaveReceived(method, path interface{}) GomegaMatcher {
+	return receivedMatcher(method, path, -1)
+}
+
+// HaveReceivedTimes returns a GomegaMatcher checking that a *Server with the RecordHistory option has received exactly
+// times requests for method and path, like HaveReceived.
+func HaveReceivedTimes(method, path interface{}, times int) GomegaMatcher {
+	return receivedMatcher(method, path, times)
+}
+
+func receivedMatcher(method, path interface{}, times int) GomegaMatcher {
+	expected := mock.Arguments{method, path}
+	count := func(actual interface{}) (int, error) {
+		s, ok := actual.(*Server)
+		if !ok {
+			return 0, fmt.Errorf("httpmock: HaveReceived expects a *httpmock.Server, got %T", actual)
+		}
+		if s.history == nil {
+			return 0, fmt.Errorf("httpmock: HaveReceived requires the RecordHistory option")
+		}
+		n := 0
+		for _, req := range s.History() {
+			if _, diffs := expected.Diff([]interface{}{req.Method, req.Path}); diffs == 0 {
+				n++
+			}
+		}
+		return n, nil
+	}
+	return GomegaMatcher{
+		match: func(actual interface{}) (bool, error) {
+			n, err := count(actual)
+			if times < 0 {
+				return n > 0, err
+			}
+			return n == times, err
+		},
+		message: func(actual interface{}, negated bool) string {
+			n, _ := count(actual)
+			not := ""
+			if negated {
+				not = "not "
+			}
+			if times < 0 {
+				return fmt.Sprintf("Expected the server %sto have received %v %v, but it received %d such requests",
+					not, method, path, n)
+			}
+			return fmt.Sprintf("Expected the server %sto have received %v %v %d times, but it received %d such requests",
+				not, method, path, times, n)
+		},
+	}
+}
+
+// MatchArgument returns a GomegaMatcher checking a value with an argument matcher of this package, such as
+// JSONMatcher or HeaderMatcherExactCase, or of testify, such as mock.MatchedBy.
+func MatchArgument(matcher interface{}) GomegaMatcher {
+	expected := mock.Arguments{matcher}
+	return GomegaMatcher{
+		match: func(actual interface{}) (bool, error) {
+			_, diffs := expected.Diff([]interface{}{actual})
+			return diffs == 0, nil
+		},
+		message: func(actual interface{}, negated bool) string {
+			diff, _ := expected.Diff([]interface{}{actual})
+			if negated {
+				return fmt.Sprintf("Expected %v not to match, but it did", actual)
+			}
+			return fmt.Sprintf("Expected %v to match:%s", actual, diff)
+		},
+	}
+}
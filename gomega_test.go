@@ -0,0 +1,53 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// gomegaMatcher is Gomega's types.GomegaMatcher interface.
+type gomegaMatcher interface {
+	Match(actual interface{}) (success bool, err error)
+	FailureMessage(actual interface{}) (message string)
+	NegatedFailureMessage(actual interface{}) (message string)
+}
+
+func TestGomegaMatchers(t *testing.T) {
+	s := NewServer(&OKHandler{}, RecordHistory())
+	defer s.Close()
+	for _, path := range []string{"/orders/1", "/orders/2"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	var m gomegaMatcher = HaveReceived("GET", "/orders/1")
+	ok, err := m.Match(s)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	m = HaveReceived("POST", "/orders/1")
+	ok, err = m.Match(s)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "Expected the server to have received POST /orders/1, but it received 0 such requests",
+		m.FailureMessage(s))
+
+	m = HaveReceivedTimes(mock.Anything, PathPrefixMatcher("/orders/"), 2)
+	ok, _ = m.Match(s)
+	assert.True(t, ok)
+
+	_, err = HaveReceived("GET", "/").Match(NewUnstartedServer(&OKHandler{}))
+	assert.EqualError(t, err, "httpmock: HaveReceived requires the RecordHistory option")
+
+	m = MatchArgument(JSONMatcher(&struct{ ID int }{ID: 1}))
+	ok, _ = m.Match([]byte(`{"ID": 1}`))
+	assert.True(t, ok)
+	ok, _ = m.Match([]byte(`{"ID": 2}`))
+	assert.False(t, ok)
+	assert.Contains(t, m.FailureMessage([]byte(`{"ID": 2}`)), "to match")
+}
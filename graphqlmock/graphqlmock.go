@@ -0,0 +1,345 @@
+/*
+Package graphqlmock answers GraphQL queries with responses generated from a schema, so exploratory client tests don't
+need a stub per query shape. Queries are parsed and validated with gqlparser. It is a separate module to keep gqlparser
+out of the dependencies of httpmock itself.
+
+	h, err := graphqlmock.NewHandler(`
+		type Query { user(id: ID!): User }
+		type User { id: ID! name: String! }`)
+	h.Resolvers["User.name"] = func(args map[string]interface{}) interface{} { return "Ada" }
+
+	s := httpmock.NewServer(h)
+	defer s.Close()
+*/
+package graphqlmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dankinder/httpmock"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// Handler is an httpmock.Handler answering GraphQL queries. Every selected field gets a syntactically valid
+// placeholder value of its type: strings and custom scalars are set to the field name, IDs to "1", Ints to 1, Floats
+// to 1.5, Booleans to true, enums to their first value, interfaces and unions to their first possible type, and lists
+// to ListLength elements. Resolvers can override the value of any field. Fields appear in the order of the query, and
+// fields selected several times under the same name are merged, as the GraphQL spec requires.
+//
+// Queries are accepted as POSTed JSON ({"query": ..., "variables": ..., "operationName": ...}), as a POSTed raw query,
+// or in the query parameter of a GET. Queries that fail to parse get a 400 response, and queries that are invalid
+// against the schema get the errors reported by gqlparser.
+type Handler struct {
+	// Resolvers override generated values, keyed by "Type.field", e.g. "Query.user". A resolver gets the field's
+	// arguments (with variables substituted) and returns its value. For object-typed fields, a returned
+	// map[string]interface{} (or a list of them) is used as a base: selected fields it doesn't have are generated.
+	Resolvers map[string]Resolver
+	// The number of elements generated for list fields (default: 1)
+	ListLength int
+
+	schema *ast.Schema
+}
+
+// Resolver computes the value of a field from its arguments, see Handler.Resolvers.
+type Resolver func(args map[string]interface{}) interface{}
+
+// NewHandler returns a Handler for the schema described in the GraphQL schema definition language. It returns an
+// error if the schema can't be parsed or is invalid.
+func NewHandler(sdl string) (*Handler, error) {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema", Input: sdl})
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{Resolvers: make(map[string]Resolver), ListLength: 1, schema: schema}, nil
+}
+
+// Handle makes this implement the httpmock.Handler interface.
+func (h *Handler) Handle(method, path string, body []byte) httpmock.Response {
+	var params struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables"`
+		OperationName string                 `json:"operationName"`
+	}
+	if method == http.MethodGet {
+		u, err := url.ParseRequestURI(path)
+		if err != nil {
+			return errorsResponse(http.StatusBadRequest, gqlerror.List{gqlerror.Wrap(err)})
+		}
+		q := u.Query()
+		params.Query, params.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &params.Variables); err != nil {
+				return errorsResponse(http.StatusBadRequest, gqlerror.List{gqlerror.Wrap(err)})
+			}
+		}
+	} else if err := json.Unmarshal(body, &params); err != nil {
+		params.Query = string(body)
+	}
+
+	doc, err := parser.ParseQuery(&ast.Source{Name: "query", Input: params.Query})
+	if err != nil {
+		return errorsResponse(http.StatusBadRequest, gqlerror.List{gqlerror.WrapIfUnwrapped(err)})
+	}
+	if errs := validator.ValidateWithRules(h.schema, doc, nil); len(errs) > 0 {
+		return errorsResponse(http.StatusOK, errs)
+	}
+	data, err := h.execute(doc, params.OperationName, params.Variables)
+	if err != nil {
+		return errorsResponse(http.StatusOK, gqlerror.List{gqlerror.WrapIfUnwrapped(err)})
+	}
+	return httpmock.Response{
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   httpmock.ToJSON(object{{"data", data}}),
+	}
+}
+
+func errorsResponse(status int, errs gqlerror.List) httpmock.Response {
+	return httpmock.Response{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   httpmock.ToJSON(map[string]interface{}{"errors": errs}),
+	}
+}
+
+// object is a JSON object whose members keep their order, since GraphQL results follow the order of the query.
+type object []member
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type execution struct {
+	h         *Handler
+	doc       *ast.QueryDocument
+	variables map[string]interface{}
+}
+
+func (h *Handler) execute(doc *ast.QueryDocument, opName string, vars map[string]interface{}) (interface{}, error) {
+	op := doc.Operations.ForName(opName)
+	if op == nil {
+		if opName == "" {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return nil, fmt.Errorf("unknown operation %q", opName)
+	}
+	var root *ast.Definition
+	switch op.Operation {
+	case ast.Query:
+		root = h.schema.Query
+	case ast.Mutation:
+		root = h.schema.Mutation
+	default:
+		return nil, fmt.Errorf("%s operations are not supported", op.Operation)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("schema has no %s type", op.Operation)
+	}
+	variables, err := validator.VariableValues(h.schema, op, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &execution{h: h, doc: doc, variables: variables}
+	return e.object(root, []ast.SelectionSet{op.SelectionSet}, nil)
+}
+
+// object generates the fields selected by the selection sets of an object, interface or union, using base for the
+// fields it has.
+func (e *execution) object(def *ast.Definition, sets []ast.SelectionSet, base map[string]interface{}) (object, error) {
+	if def.Kind == ast.Interface || def.Kind == ast.Union {
+		concrete := e.possibleObjects(def)
+		if len(concrete) == 0 {
+			return nil, fmt.Errorf("%s has no possible types", def.Name)
+		}
+		resolved := concrete[0]
+		if name, ok := base["__typename"].(string); ok {
+			for _, possible := range concrete {
+				if possible.Name == name {
+					resolved = possible
+				}
+			}
+		}
+		def = resolved
+	}
+
+	groups := &fieldGroups{fields: make(map[string][]*ast.Field)}
+	for _, set := range sets {
+		e.collectFields(def, set, groups)
+	}
+	result := make(object, 0, len(groups.keys))
+	for _, key := range groups.keys {
+		group := groups.fields[key]
+		field := group[0]
+		if field.Name == "__typename" {
+			result = append(result, member{key, def.Name})
+			continue
+		}
+		var value interface{}
+		if v, ok := base[field.Name]; ok {
+			value = v
+		} else if resolver, ok := e.h.Resolvers[def.Name+"."+field.Name]; ok {
+			value = resolver(field.ArgumentMap(e.variables))
+		}
+		v, err := e.value(group, field.Definition.Type, value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, member{key, v})
+	}
+	return result, nil
+}
+
+// fieldGroups holds the fields selected on an object, grouped by response key.
+type fieldGroups struct {
+	// The response keys in the order they first appear
+	keys   []string
+	fields map[string][]*ast.Field
+}
+
+// collectFields adds the fields that set selects on def to groups, following fragments whose type condition def
+// satisfies and skipping fields excluded by @skip or @include.
+func (e *execution) collectFields(def *ast.Definition, set ast.SelectionSet, groups *fieldGroups) {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if !e.included(sel.Directives) {
+				continue
+			}
+			key := sel.Alias
+			if key == "" {
+				key = sel.Name
+			}
+			if _, ok := groups.fields[key]; !ok {
+				groups.keys = append(groups.keys, key)
+			}
+			groups.fields[key] = append(groups.fields[key], sel)
+		case *ast.InlineFragment:
+			if e.included(sel.Directives) && e.applies(def, sel.TypeCondition) {
+				e.collectFields(def, sel.SelectionSet, groups)
+			}
+		case *ast.FragmentSpread:
+			fragment := e.doc.Fragments.ForName(sel.Name)
+			if e.included(sel.Directives) && fragment != nil && e.applies(def, fragment.TypeCondition) {
+				e.collectFields(def, fragment.SelectionSet, groups)
+			}
+		}
+	}
+}
+
+// included reports whether the @skip and @include directives of a selection let it through.
+func (e *execution) included(directives ast.DirectiveList) bool {
+	if d := directives.ForName("skip"); d != nil && d.ArgumentMap(e.variables)["if"] == true {
+		return false
+	}
+	if d := directives.ForName("include"); d != nil && d.ArgumentMap(e.variables)["if"] == false {
+		return false
+	}
+	return true
+}
+
+// applies reports whether the object type def satisfies a fragment's type condition.
+func (e *execution) applies(def *ast.Definition, typeCondition string) bool {
+	if typeCondition == "" || typeCondition == def.Name {
+		return true
+	}
+	for _, possible := range e.h.schema.PossibleTypes[typeCondition] {
+		if possible.Name == def.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// possibleObjects returns the object types that satisfy the interface or union def, in the order they were defined.
+func (e *execution) possibleObjects(def *ast.Definition) []*ast.Definition {
+	var objects []*ast.Definition
+	for _, possible := range e.h.schema.GetPossibleTypes(def) {
+		if possible.Kind == ast.Object {
+			objects = append(objects, possible)
+		}
+	}
+	return objects
+}
+
+// value generates the value of a field of type t, selected as the fields of group, starting from the resolved value if
+// there is one. The subfields of object types are those of all the fields in the group.
+func (e *execution) value(group []*ast.Field, t *ast.Type, resolved interface{}) (interface{}, error) {
+	if t.Elem != nil {
+		items, ok := resolved.([]interface{})
+		if !ok {
+			items = make([]interface{}, e.h.ListLength)
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := e.value(group, t.Elem, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	}
+
+	def := e.h.schema.Types[t.NamedType]
+	switch {
+	case def == nil:
+		return nil, fmt.Errorf("unknown type %s", t.NamedType)
+	case def.Kind == ast.Object || def.Kind == ast.Interface || def.Kind == ast.Union:
+		sets := make([]ast.SelectionSet, len(group))
+		for i, field := range group {
+			sets[i] = field.SelectionSet
+		}
+		base, _ := resolved.(map[string]interface{})
+		return e.object(def, sets, base)
+	case resolved != nil:
+		return resolved, nil
+	case def.Kind == ast.Enum:
+		if len(def.EnumValues) == 0 {
+			return nil, fmt.Errorf("enum %s has no values", def.Name)
+		}
+		return def.EnumValues[0].Name, nil
+	}
+	switch def.Name {
+	case "ID":
+		return "1", nil
+	case "Int":
+		return 1, nil
+	case "Float":
+		return 1.5, nil
+	case "Boolean":
+		return true, nil
+	}
+	return group[0].Name, nil
+}
@@ -0,0 +1,169 @@
+package graphqlmock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+"""The root query"""
+type Query {
+	user(id: ID!): User
+	search(term: String = "x"): [SearchResult!]!
+	node(id: ID!): Node @deprecated(reason: "use user")
+}
+
+type Mutation {
+	rename(id: ID!, name: String!): User!
+}
+
+interface Node { id: ID! }
+
+type User implements Node {
+	id: ID!
+	name: String!
+	age: Int
+	score: Float
+	admin: Boolean!
+	role: Role!
+	friends(first: Int): [User!]!
+}
+
+type Post implements Node {
+	id: ID!
+	title: String!
+}
+
+union SearchResult = Post | User
+
+enum Role { ADMIN MEMBER }
+`
+
+func postGraphQL(t *testing.T, s *httpmock.Server, query string, vars map[string]interface{}) map[string]interface{} {
+	resp, err := http.Post(s.URL()+"/graphql", "application/json",
+		strings.NewReader(string(httpmock.ToJSON(map[string]interface{}{"query": query, "variables": vars}))))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &result))
+	return result
+}
+
+func TestHandler(t *testing.T) {
+	h, err := NewHandler(testSchema)
+	require.NoError(t, err)
+	h.ListLength = 2
+	h.Resolvers["Query.user"] = func(args map[string]interface{}) interface{} {
+		return map[string]interface{}{"id": args["id"], "friends": []interface{}{map[string]interface{}{"name": "Grace"}}}
+	}
+	h.Resolvers["User.name"] = func(map[string]interface{}) interface{} { return "Ada" }
+
+	s := httpmock.NewServer(h)
+	defer s.Close()
+
+	result := postGraphQL(t, s, `
+		query GetUser($id: ID!) {
+			user(id: $id) {
+				id
+				fullName: name
+				age score admin role
+				friends(first: 1) { name ...Ids }
+				__typename
+			}
+		}
+		fragment Ids on Node { id }`, map[string]interface{}{"id": "42"})
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"user": map[string]interface{}{
+			"id":         "42",
+			"fullName":   "Ada",
+			"age":        1.0,
+			"score":      1.5,
+			"admin":      true,
+			"role":       "ADMIN",
+			"friends":    []interface{}{map[string]interface{}{"name": "Grace", "id": "1"}},
+			"__typename": "User",
+		},
+	}, result["data"])
+
+	result = postGraphQL(t, s, `{ search { __typename ... on Post { title } } node(id: "1") { id } }`, nil)
+	assert.Equal(t, map[string]interface{}{
+		"search": []interface{}{
+			map[string]interface{}{"__typename": "Post", "title": "title"},
+			map[string]interface{}{"__typename": "Post", "title": "title"},
+		},
+		"node": map[string]interface{}{"id": "1"},
+	}, result["data"])
+
+	result = postGraphQL(t, s, `mutation { rename(id: "1", name: "Bob") { name } }`, nil)
+	assert.Equal(t, map[string]interface{}{"rename": map[string]interface{}{"name": "Ada"}}, result["data"])
+
+	result = postGraphQL(t, s, `{ user(id: "1") { email } }`, nil)
+	assert.Nil(t, result["data"])
+	assert.Equal(t, `Cannot query field "email" on type "User".`,
+		result["errors"].([]interface{})[0].(map[string]interface{})["message"])
+
+	resp, err := http.Get(s.URL() + "/graphql?query=" + url.QueryEscape(`{ user(id: "1") { admin } }`))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":{"user":{"admin":true}}}`, string(body))
+}
+
+func TestFragmentCycles(t *testing.T) {
+	h, err := NewHandler(testSchema)
+	require.NoError(t, err)
+	s := httpmock.NewServer(h)
+	defer s.Close()
+
+	for _, c := range []struct{ query, message string }{
+		{`{ user(id: "1") { ...A } } fragment A on User { id ...A }`, `Cannot spread fragment "A" within itself.`},
+		{
+			`{ user(id: "1") { ...A } } fragment A on User { friends { ...B } } fragment B on User { ...A }`,
+			`Cannot spread fragment "A" within itself via "B".`,
+		},
+	} {
+		result := postGraphQL(t, s, c.query, nil)
+		assert.Nil(t, result["data"])
+		assert.Equal(t, c.message, result["errors"].([]interface{})[0].(map[string]interface{})["message"])
+	}
+
+	query := `{ user(id: "1") { ...A ...B } } fragment A on User { ...B } fragment B on User { id }`
+	result := postGraphQL(t, s, query, nil)
+	assert.Nil(t, result["errors"])
+}
+
+func TestFieldOrderAndMerging(t *testing.T) {
+	h, err := NewHandler(testSchema)
+	require.NoError(t, err)
+	s := httpmock.NewServer(h)
+	defer s.Close()
+
+	resp, err := http.Post(s.URL()+"/graphql", "application/graphql", strings.NewReader(`{
+		user(id: "1") {
+			name
+			id
+			friends { id }
+			... on User { friends { name } }
+			admin @skip(if: true)
+		}
+	}`))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"user":{"name":"name","id":"1","friends":[{"id":"1","name":"name"}]}}}`, string(body))
+
+	resp, err = http.Post(s.URL()+"/graphql", "application/graphql", strings.NewReader(`{ user(id: "1") { id `))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,116 @@
+package httpmock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// gRPC-Web frame flags, see https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md
+const (
+	grpcWebCompressedFlag = 0x01
+	grpcWebTrailerFlag    = 0x80
+)
+
+// GRPCWebResponse returns a Response in the gRPC-Web wire format: each message (e.g. a marshaled protobuf) in a
+// length-prefixed data frame, followed by a trailer frame carrying the gRPC status code and message. The HTTP status
+// is always 200, as gRPC reports errors in the trailers. Pass code 0 (OK) for a successful call.
+//
+//	downstream.On("Handle", "POST", "/shop.Catalog/GetItem", mock.Anything).Return(
+//		httpmock.GRPCWebResponse([][]byte{itemBytes}, 0, ""))
+func GRPCWebResponse(messages [][]byte, code int, message string) Response {
+	var buf bytes.Buffer
+	for _, m := range messages {
+		writeGRPCWebFrame(&buf, 0, m)
+	}
+	trailers := "grpc-status: " + strconv.Itoa(code) + "\r\n"
+	if message != "" {
+		trailers += "grpc-message: " + grpcPercentEncode(message) + "\r\n"
+	}
+	writeGRPCWebFrame(&buf, grpcWebTrailerFlag, []byte(trailers))
+
+	return Response{
+		Header: http.Header{"Content-Type": {"application/grpc-web+proto"}},
+		Body:   buf.Bytes(),
+	}
+}
+
+// GRPCWebFrames parses a gRPC-Web body into its messages and trailers (nil if there is no trailer frame). Request
+// bodies carry only messages; response bodies end with trailers. Compressed frames are not supported.
+func GRPCWebFrames(body []byte) (messages [][]byte, trailers http.Header, err error) {
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return nil, nil, errors.New("truncated gRPC-Web frame header")
+		}
+		flags, length := body[0], binary.BigEndian.Uint32(body[1:5])
+		if uint64(length) > uint64(len(body)-5) {
+			return nil, nil, errors.New("truncated gRPC-Web frame")
+		}
+		payload := body[5 : 5+length]
+		body = body[5+length:]
+
+		switch {
+		case flags&grpcWebCompressedFlag != 0:
+			return nil, nil, errors.New("compressed gRPC-Web frames are not supported")
+		case flags&grpcWebTrailerFlag != 0:
+			// Copy the payload, as appending in place would overwrite the frame following it in body
+			block := append(append([]byte(nil), payload...), "\r\n"...)
+			r := textproto.NewReader(bufio.NewReader(bytes.NewReader(block)))
+			h, err := r.ReadMIMEHeader()
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid gRPC-Web trailers: %v", err)
+			}
+			trailers = http.Header(h)
+		default:
+			messages = append(messages, payload)
+		}
+	}
+	return messages, trailers, nil
+}
+
+// GRPCWebMessageMatcher returns a mock.MatchedBy func to check if the body argument is a gRPC-Web request carrying
+// exactly the given messages, e.g. marshaled protobufs. Since protobuf encoding is deterministic for a given message
+// and library, comparing bytes is usually enough for unary and client-streaming calls.
+func GRPCWebMessageMatcher(messages ...[]byte) interface{} {
+	return mock.MatchedBy(func(body []byte) bool {
+		actual, _, err := GRPCWebFrames(body)
+		if err != nil || len(actual) != len(messages) {
+			return false
+		}
+		for i := range messages {
+			if !bytes.Equal(actual[i], messages[i]) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func writeGRPCWebFrame(buf *bytes.Buffer, flags byte, payload []byte) {
+	buf.WriteByte(flags)
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(payload)))
+	buf.Write(payload)
+}
+
+// grpcPercentEncode encodes a grpc-message value as required by the gRPC protocol: printable ASCII other than '%' is
+// kept, everything else is percent-encoded.
+func grpcPercentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c <= 0x7e && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package httpmock
+
+import (
+	"sync"
+	"time"
+)
+
+// RecordedRequest is a request received by a Server with the RecordHistory option, along with how it was handled.
+type RecordedRequest struct {
+	RequestInfo
+	// The response sent to the client (zero while the request is still being handled)
+	Response Response
+	// When the server started handling the request, i.e. once its headers were read
+	Start time.Time
+	// When the server finished writing the response (zero while the request is still being handled)
+	End time.Time
+	// Whether the request body and the response body were cut short or dropped, to stay within the HistoryLimits
+	BodyTruncated, ResponseBodyTruncated bool
+}
+
+// Duration returns how long the server took to handle the request, or zero if it is still being handled.
+func (r RecordedRequest) Duration() time.Duration {
+	if r.End.IsZero() {
+		return 0
+	}
+	return r.End.Sub(r.Start)
+}
+
+// RecordHistory makes the server record every request it receives, see Server.History.
+func RecordHistory() Option {
+	return func(s *Server) {
+		s.history = &history{}
+	}
+}
+
+// HistoryLimits bounds the memory used by the request and response bodies in a server's history, for long-running
+// tests streaming large payloads. Bodies cut short or dropped are flagged in their RecordedRequest. Zero fields mean no
+// limit.
+type HistoryLimits struct {
+	// The number of most recent requests whose bodies are kept
+	MaxBodies int
+	// The number of bytes kept of each body
+	MaxBodyBytes int
+	// The total number of body bytes kept, the bodies of the oldest requests being dropped first
+	MaxTotalBytes int
+}
+
+// RecordHistoryWithLimits makes the server record every request it receives like RecordHistory, within the given
+// limits.
+//
+//	s := httpmock.NewServer(downstream, httpmock.RecordHistoryWithLimits(httpmock.HistoryLimits{
+//		MaxBodyBytes:  4 << 10,
+//		MaxTotalBytes: 16 << 20,
+//	}))
+func RecordHistoryWithLimits(limits HistoryLimits) Option {
+	return func(s *Server) {
+		s.history = &history{limits: limits}
+	}
+}
+
+// History returns the requests received so far, in the order they arrived, including requests that are still being
+// handled. It returns nil unless the server was created with the RecordHistory or RecordHistoryWithLimits option.
+func (s *Server) History() []RecordedRequest {
+	if s.history == nil {
+		return nil
+	}
+	s.history.mu.Lock()
+	defer s.history.mu.Unlock()
+	return append([]RecordedRequest(nil), s.history.requests...)
+}
+
+// history holds the requests recorded by a server with the RecordHistory option.
+type history struct {
+	mu       sync.Mutex
+	requests []RecordedRequest
+	limits   HistoryLimits
+
+	// The requests before this index have had their bodies dropped
+	keptFrom int
+	// The total size of the bodies kept
+	keptBytes int
+}
+
+// reset forgets all recorded requests.
+func (h *history) reset() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = nil
+	h.keptFrom = 0
+	h.keptBytes = 0
+}
+
+// start records that req started being handled, returning its index for end.
+func (h *history) start(req RequestInfo) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recorded := RecordedRequest{RequestInfo: req, Start: time.Now()}
+	recorded.Body, recorded.BodyTruncated = h.keep(req.Body)
+	h.requests = append(h.requests, recorded)
+	h.enforceLimits()
+	return len(h.requests) - 1
+}
+
+// end records that the request at index i was answered with resp.
+func (h *history) end(i int, resp Response) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r := &h.requests[i]
+	r.Response = resp
+	r.End = time.Now()
+	if i < h.keptFrom {
+		r.Response.Body, r.ResponseBodyTruncated = nil, len(resp.Body) > 0
+		return
+	}
+	r.Response.Body, r.ResponseBodyTruncated = h.keep(resp.Body)
+	h.enforceLimits()
+}
+
+// keep returns the part of body to record, copied if it was cut short so that the rest can be freed, and whether it
+// was, accounting for its size.
+func (h *history) keep(body []byte) ([]byte, bool) {
+	truncated := false
+	if h.limits.MaxBodyBytes > 0 && len(body) > h.limits.MaxBodyBytes {
+		body = append([]byte(nil), body[:h.limits.MaxBodyBytes]...)
+		truncated = true
+	}
+	h.keptBytes += len(body)
+	return body, truncated
+}
+
+// enforceLimits drops the bodies of the oldest requests until the bodies kept are within the limits.
+func (h *history) enforceLimits() {
+	for h.keptFrom < len(h.requests) &&
+		((h.limits.MaxBodies > 0 && len(h.requests)-h.keptFrom > h.limits.MaxBodies) ||
+			(h.limits.MaxTotalBytes > 0 && h.keptBytes > h.limits.MaxTotalBytes)) {
+		r := &h.requests[h.keptFrom]
+		h.keptBytes -= len(r.Body) + len(r.Response.Body)
+		if len(r.Body) > 0 {
+			r.Body, r.BodyTruncated = nil, true
+		}
+		if len(r.Response.Body) > 0 {
+			r.Response.Body, r.ResponseBodyTruncated = nil, true
+		}
+		h.keptFrom++
+	}
+}
@@ -0,0 +1,107 @@
+package httpmock
+
+import (
+	"net/http"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// HistoryMatcher returns a mock.MatchedBy func to check the RequestInfo argument of HandleRequest with fn, which also
+// receives the requests the server received before it, in order. This allows expectations that depend on earlier
+// requests, which stateless matchers can't express. The server must have been created with the RecordHistory option;
+// otherwise, and for requests not received by this server, prior is empty.
+//
+//	downstream.On("HandleRequest", s.HistoryMatcher(func(prior []httpmock.RecordedRequest, req httpmock.RequestInfo) bool {
+//		return len(prior) > 0 && prior[len(prior)-1].Path == "/login"
+//	}))
+func (s *Server) HistoryMatcher(fn func(prior []RecordedRequest, req RequestInfo) bool) interface{} {
+	return mock.MatchedBy(func(req RequestInfo) bool {
+		return fn(s.priorRequests(req), req)
+	})
+}
+
+// CalledBefore returns a mock.MatchedBy func to check if the server received a request with the given method and
+// path (ignoring the query string) before the RequestInfo argument of HandleRequest, e.g. to only serve a resource
+// once the client has logged in. See HistoryMatcher.
+func (s *Server) CalledBefore(method, path string) interface{} {
+	return s.HistoryMatcher(func(prior []RecordedRequest, req RequestInfo) bool {
+		for _, p := range prior {
+			if p.Method == method && stripQuery(p.Path) == path {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// NthRequestMatcher returns a mock.MatchedBy func to check if the RequestInfo argument of HandleRequest satisfies fn
+// and is the nth such request the server received, counting from 1, e.g. to answer the second identical POST
+// differently than the first. See HistoryMatcher.
+func (s *Server) NthRequestMatcher(n int, fn func(req RequestInfo) bool) interface{} {
+	return s.HistoryMatcher(func(prior []RecordedRequest, req RequestInfo) bool {
+		if !fn(req) {
+			return false
+		}
+		count := 1
+		for _, p := range prior {
+			if fn(p.RequestInfo) {
+				count++
+			}
+		}
+		return count == n
+	})
+}
+
+// ResponseFromHistory returns a Response that is computed by fn when it is served, from the request and the requests
+// the server received before it, giving lightweight statefulness without writing a custom handler. Like
+// HistoryMatcher, it requires the RecordHistory option.
+//
+//	downstream.On("Handle", "GET", "/counter", mock.Anything).Return(s.ResponseFromHistory(
+//		func(prior []httpmock.RecordedRequest, req httpmock.RequestInfo) httpmock.Response {
+//			return httpmock.Response{BodyObject: len(prior)}
+//		}))
+func (s *Server) ResponseFromHistory(fn func(prior []RecordedRequest, req RequestInfo) Response) Response {
+	return Response{compute: func(req RequestInfo) Response {
+		return fn(s.priorRequests(req), req)
+	}}
+}
+
+// LastWrittenBody returns a Response that is computed when it is served, echoing the body (and Content-Type) of the
+// last POST, PUT or PATCH the server received for the same path, or a 404 if there was none. Together with an
+// expectation for the writes, it makes a simple stateful resource. See ResponseFromHistory.
+//
+//	downstream.On("Handle", "PUT", "/settings", mock.Anything).Return(httpmock.Response{Status: 204})
+//	downstream.On("Handle", "GET", "/settings", mock.Anything).Return(s.LastWrittenBody())
+func (s *Server) LastWrittenBody() Response {
+	return s.ResponseFromHistory(func(prior []RecordedRequest, req RequestInfo) Response {
+		path := stripQuery(req.Path)
+		for i := len(prior) - 1; i >= 0; i-- {
+			p := prior[i]
+			switch p.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+				if stripQuery(p.Path) != path {
+					continue
+				}
+				resp := Response{Body: p.Body}
+				if ct := p.Header.Get("Content-Type"); ct != "" {
+					resp.Header = http.Header{"Content-Type": {ct}}
+				}
+				return resp
+			}
+		}
+		return Response{Status: http.StatusNotFound}
+	})
+}
+
+// priorRequests returns the requests recorded before req.
+func (s *Server) priorRequests(req RequestInfo) []RecordedRequest {
+	if s.history == nil || req.seq == 0 {
+		return nil
+	}
+	s.history.mu.Lock()
+	defer s.history.mu.Unlock()
+	if req.seq > len(s.history.requests) {
+		return nil
+	}
+	return append([]RecordedRequest(nil), s.history.requests[:req.seq-1]...)
+}
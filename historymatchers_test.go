@@ -0,0 +1,80 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryMatchers(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+	s := NewServer(downstream, RecordHistory())
+	defer s.Close()
+
+	isOrder := func(req RequestInfo) bool {
+		return req.Method == "POST" && req.Path == "/orders" && string(req.Body) == "widget"
+	}
+	downstream.On("HandleRequest", s.NthRequestMatcher(2, isOrder)).Return(Response{Status: 409})
+	downstream.On("HandleRequest", s.CalledBefore("POST", "/login")).Return(Response{Status: 200})
+	downstream.On("HandleRequest", mock.Anything).Return(Response{Status: 401})
+
+	post := func(path, body string) int {
+		resp, err := http.Post(s.URL()+path, "text/plain", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, 401, post("/orders", "widget"))
+	assert.Equal(t, 401, post("/login", ""))
+	assert.Equal(t, 409, post("/orders", "widget"))
+	assert.Equal(t, 200, post("/orders", "widget"))
+	assert.Equal(t, 200, post("/orders", "gadget"))
+
+	downstream.AssertExpectations(t)
+}
+
+func TestLastWrittenBody(t *testing.T) {
+	downstream := NewMockHandler(t)
+	s := NewServer(downstream, RecordHistory())
+	defer s.Close()
+
+	downstream.On("Handle", "PUT", "/settings", mock.Anything).Return(Response{Status: 204})
+	downstream.On("Handle", "GET", "/settings", mock.Anything).Return(s.LastWrittenBody())
+	downstream.On("Handle", "GET", "/count", mock.Anything).Return(s.ResponseFromHistory(
+		func(prior []RecordedRequest, req RequestInfo) Response {
+			return Response{BodyObject: len(prior)}
+		}))
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode, string(body)
+	}
+	put := func(body string) {
+		req, err := http.NewRequest("PUT", s.URL()+"/settings", strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	status, _ := get("/settings")
+	assert.Equal(t, 404, status)
+	put(`{"theme":"dark"}`)
+	put(`{"theme":"light"}`)
+	status, body := get("/settings")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, `{"theme":"light"}`, body)
+	_, body = get("/count")
+	assert.Equal(t, "4", body)
+
+	downstream.AssertExpectations(t)
+}
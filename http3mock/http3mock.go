@@ -0,0 +1,130 @@
+/*
+Package http3mock serves httpmock servers over HTTP/3, using quic-go, so that HTTP/3-capable clients can be tested
+against a mock. It is a separate module to keep quic-go out of the dependencies of httpmock itself. HTTP/3 support is
+experimental.
+
+	downstream := httpmock.NewMockHandlerWithRequest(t)
+	downstream.On("HandleRequest", mock.Anything).Return(httpmock.Response{})
+
+	s := http3mock.NewServer(downstream)
+	defer s.Close()
+
+	// Make requests to s.URL() with s.Client(), then check RequestInfo.Proto is "HTTP/3.0"
+*/
+package http3mock
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dankinder/httpmock"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Server is an httpmock.Server serving HTTP/3 on the UDP port with the same number as its TCP port, the way real
+// servers offering HTTP/3 do, so URL works for both. Over TCP it serves HTTP/1.1 with TLS, as started by StartTLS,
+// which lets clients falling back from HTTP/3 be tested too. Both trust the same self-signed certificate, so the
+// TLSConfig option must not be used.
+type Server struct {
+	*httpmock.Server
+	h3      *http3.Server
+	conn    net.PacketConn
+	rootCAs *x509.CertPool
+
+	mu         sync.Mutex
+	transports []*http3.Transport
+}
+
+// NewServer constructs a new server and starts it, on TCP and UDP. It needs to be Closed()ed. It panics if the UDP port
+// matching the TCP one is taken.
+func NewServer(handler httpmock.Handler, opts ...httpmock.Option) *Server {
+	cert, err := selfSignedCert()
+	if err != nil {
+		panic("http3mock: " + err.Error())
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(cert.Leaf)
+
+	opts = append(opts, httpmock.TLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	s := &Server{Server: httpmock.NewUnstartedServer(handler, opts...), rootCAs: rootCAs}
+	s.Server.StartTLS()
+
+	u, err := url.Parse(s.Server.URL())
+	if err == nil {
+		s.conn, err = net.ListenPacket("udp", u.Host)
+	}
+	if err != nil {
+		s.Server.Close()
+		panic("http3mock: " + err.Error())
+	}
+	s.h3 = &http3.Server{
+		Handler:   s.Server.HTTPHandler(),
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}),
+	}
+	go func() {
+		_ = s.h3.Serve(s.conn)
+	}()
+	return s
+}
+
+// Client returns an HTTP client making requests over HTTP/3 and trusting the server's certificate. Its connections
+// are closed with the server. Use the embedded Server's Client for requests over TCP.
+func (s *Server) Client() *http.Client {
+	transport := &http3.Transport{TLSClientConfig: &tls.Config{RootCAs: s.rootCAs}}
+	s.mu.Lock()
+	s.transports = append(s.transports, transport)
+	s.mu.Unlock()
+	return &http.Client{Transport: transport}
+}
+
+// Close shuts down the HTTP/3 server and the clients returned by Client, then the embedded Server.
+func (s *Server) Close() {
+	_ = s.h3.Close()
+	_ = s.conn.Close()
+	s.mu.Lock()
+	for _, transport := range s.transports {
+		_ = transport.Close()
+	}
+	s.transports = nil
+	s.mu.Unlock()
+	s.Server.Close()
+}
+
+// selfSignedCert returns a certificate for the loopback addresses, valid for a day.
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"httpmock"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
@@ -0,0 +1,36 @@
+package http3mock
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	downstream := httpmock.NewMockHandlerWithRequest(t)
+	requests := &httpmock.Captor[httpmock.RequestInfo]{}
+	downstream.On("HandleRequest", requests.Matcher()).Return(httpmock.Response{Body: []byte("hello")})
+
+	s := NewServer(downstream, httpmock.RecordHistory())
+	defer s.Close()
+
+	resp, err := s.Client().Get(s.URL() + "/h3")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/3.0", resp.Proto)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, "HTTP/3.0", requests.Last().Proto)
+	assert.Equal(t, "/h3", requests.Last().Path)
+
+	resp, err = s.Server.Client().Get(s.URL() + "/tcp")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", requests.Last().Proto)
+
+	assert.Len(t, s.History(), 2)
+	downstream.AssertExpectations(t)
+}
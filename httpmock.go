@@ -61,11 +61,18 @@ Httpmock also provides helpers for checking calls using json objects, like so:
 package httpmock
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
 	"io"
-	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"runtime/debug"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // Handler is the interface used by httpmock instead of http.Handler so that it can be mocked very easily.
@@ -80,6 +87,48 @@ type HandlerWithHeaders interface {
 	HandleWithHeaders(method, path string, headers http.Header, body []byte) Response
 }
 
+// HandlerWithRequest is the interface used by httpmock instead of http.Handler so that it can be mocked very easily,
+// it receives the whole request as a single RequestInfo so that matchers can look at several parts of it at once.
+type HandlerWithRequest interface {
+	Handler
+	HandleRequest(req RequestInfo) Response
+}
+
+// RequestInfo holds the parts of a received request that are passed to a HandlerWithRequest.
+type RequestInfo struct {
+	// The HTTP method, e.g. "GET"
+	Method string
+	// The request URI, i.e. the path including the query string
+	Path string
+	// The host the request was sent to, from the Host header or the absolute request URI, e.g. "example.com:8080"
+	Host string
+	// The request headers
+	Header http.Header
+	// The request body (empty if there was none)
+	Body []byte
+	// The protocol the request was received over, e.g. "HTTP/1.1", "HTTP/2.0" or "HTTP/3.0"
+	Proto string
+	// The header names in the case and order they were received in, since Header holds them canonicalized. It is nil
+	// when they aren't available: for HTTP/2 requests, whose header names are always lowercase, and for requests made
+	// over TLS, as they are recorded from the connection underneath it.
+	RawHeaderNames []string
+	// The TLS connection state, with the negotiated version and cipher suite, or nil if the request wasn't made over TLS
+	TLS *tls.ConnectionState
+	// Identifies the connection the request was received on, the requests of a connection sharing its ID. IDs are
+	// numbered from 1 in the order connections were accepted, 0 meaning unknown (e.g. when served by HTTPHandler).
+	ConnID int
+	// The addresses of the client and of the server's end of the connection, e.g. "127.0.0.1:52431", empty if unknown
+	RemoteAddr, LocalAddr string
+	// The error reading the body, e.g. if the client disconnected during the upload, in which case Body holds the
+	// part that was read. See BodyReadErrors for how the server handles it.
+	BodyErr error
+
+	// The position of the request in the server's history plus one, or 0 if it isn't recorded
+	seq int
+	// The number of times the server was Reset before the request arrived, see middlewareState
+	resets int64
+}
+
 // NewMockHandler returns a pointer to a new mock handler with the test struct set
 func NewMockHandler(t *testing.T) *MockHandler {
 	handler := &MockHandler{}
@@ -94,6 +143,37 @@ func NewMockHandlerWithHeaders(t *testing.T) *MockHandlerWithHeaders {
 	return handler
 }
 
+// NewMockHandlerWithRequest returns a pointer to a new mock handler with requests with the test struct set
+func NewMockHandlerWithRequest(t *testing.T) *MockHandlerWithRequest {
+	handler := &MockHandlerWithRequest{}
+	handler.Test(t)
+	return handler
+}
+
+// NewMockHandlerT returns a pointer to a new mock handler with the test struct set, whose expectations are asserted
+// when the test finishes, so forgetting to call AssertExpectations can't make a test pass silently
+func NewMockHandlerT(t *testing.T) *MockHandler {
+	handler := NewMockHandler(t)
+	t.Cleanup(func() { handler.AssertExpectations(t) })
+	return handler
+}
+
+// NewMockHandlerWithHeadersT returns a pointer to a new mock handler with headers with the test struct set, whose
+// expectations are asserted when the test finishes
+func NewMockHandlerWithHeadersT(t *testing.T) *MockHandlerWithHeaders {
+	handler := NewMockHandlerWithHeaders(t)
+	t.Cleanup(func() { handler.AssertExpectations(t) })
+	return handler
+}
+
+// NewMockHandlerWithRequestT returns a pointer to a new mock handler with requests with the test struct set, whose
+// expectations are asserted when the test finishes
+func NewMockHandlerWithRequestT(t *testing.T) *MockHandlerWithRequest {
+	handler := NewMockHandlerWithRequest(t)
+	t.Cleanup(func() { handler.AssertExpectations(t) })
+	return handler
+}
+
 // Response holds the response a handler wants to return to the client.
 type Response struct {
 	// The HTTP status code to write (default: 200)
@@ -102,35 +182,83 @@ type Response struct {
 	Header http.Header
 	// The response body to write (default: no body)
 	Body []byte
+	// An object to marshal to JSON when the response is served, replacing Body. The Content-Type header is set to
+	// application/json unless Header already has one. Marshaling errors are reported to the test given to
+	// ReportErrorsTo, and result in a 500 response.
+	BodyObject interface{}
+	// A custom reason phrase for the status line, e.g. "Custom" to send "299 Custom" (default: the standard phrase for
+	// the status). Setting it makes the server write the response itself on the hijacked connection, which is then
+	// closed, so it is only supported over HTTP/1.x.
+	Reason string
+	// Hijack, if set, takes over the connection once the request has been read, instead of a response being written
+	// from the other fields. It is an escape hatch for protocol upgrades and custom framing, and is only supported
+	// over HTTP/1.x. The connection is closed when it returns.
+	Hijack RawHandler
+
+	// Computes the actual response when the response is served, see Server.ResponseFromHistory
+	compute func(req RequestInfo) Response
+	// Set by Uncompressed, so that CompressResponses leaves the response alone
+	uncompressed bool
 }
 
 // Server listens for requests and interprets them into calls to your Handler.
 type Server struct {
 	httpServer *httptest.Server
+	handler    Handler
+	ready      chan struct{}
+	inflight   *inflight
+	requests   counter
+	lastConnID int64
+	resets     int64
+
+	// Set by options
+	reportErrorsTo TestingT
+	middleware     []Middleware
+	history        *history
+	latencies      *latencies
+	listener       net.Listener
+	dial           func(ctx context.Context) (net.Conn, error)
+	tlsConfig      *tls.Config
+	onPanic        func(req RequestInfo, value interface{}, stack []byte)
+	deferred       *deferredFailures
+	timeouts       serverTimeouts
+	readFaults     []readFault
+	bodyErrors     BodyReadPolicy
+	ignored        [][]string
+	metrics        *metrics
+	logger         Logger
+	onBodyError    func(req RequestInfo, err error)
 }
 
 // NewServer constructs a new server and starts it (compare to httptest.NewServer). It needs to be Closed()ed.
-// If you pass a handler that conforms to the HandlerWithHeaders interface, when requests are received, the
-// HandleWithHeaders method will be called rather than Handle.
-func NewServer(handler Handler) *Server {
-	s := NewUnstartedServer(handler)
+// If you pass a handler that conforms to the HandlerWithRequest or HandlerWithHeaders interface, when requests are
+// received, the HandleRequest or HandleWithHeaders method will be called rather than Handle.
+func NewServer(handler Handler, opts ...Option) *Server {
+	s := NewUnstartedServer(handler, opts...)
 	s.Start()
 	return s
 }
 
 // NewUnstartedServer constructs a new server but doesn't start it (compare to httptest.NewUnstartedServer).
-// If you pass a handler that conforms to the HandlerWithHeaders interface, when requests are received, the
-// HandleWithHeaders method will be called rather than Handle.
-func NewUnstartedServer(handler Handler) *Server {
-	converter := &httpToHTTPMockHandler{}
-	if hh, ok := handler.(HandlerWithHeaders); ok {
-		converter.handlerWithHeaders = hh
-	} else {
-		converter.handler = handler
+// If you pass a handler that conforms to the HandlerWithRequest or HandlerWithHeaders interface, when requests are
+// received, the HandleRequest or HandleWithHeaders method will be called rather than Handle.
+func NewUnstartedServer(handler Handler, opts ...Option) *Server {
+	s := &Server{handler: handler, ready: make(chan struct{}), inflight: newInflight()}
+	for _, opt := range opts {
+		opt(s)
 	}
-	s := &Server{
-		httpServer: httptest.NewUnstartedServer(converter),
+
+	converter := &httpToHTTPMockHandler{server: s}
+	if s.listener != nil {
+		s.httpServer = &httptest.Server{Listener: s.listener, Config: &http.Server{Handler: converter}}
+	} else {
+		s.httpServer = httptest.NewUnstartedServer(converter)
 	}
+	s.httpServer.Listener = rawHeaderListener{s.httpServer.Listener}
+	s.httpServer.Config.ConnContext = s.connContext
+	s.httpServer.Config.ConnState = s.inflight.connState
+	s.httpServer.TLS = s.tlsConfig
+	s.timeouts.apply(s.httpServer.Config)
 
 	return s
 }
@@ -138,11 +266,83 @@ func NewUnstartedServer(handler Handler) *Server {
 // Start starts an unstarted server.
 func (s *Server) Start() {
 	s.httpServer.Start()
+	close(s.ready)
+}
+
+// Ready returns a channel that is closed once the server has been started and is accepting requests, for harnesses
+// that start it from another goroutine.
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
 }
 
-// Close shuts down a started server.
+// ServeUntil starts the server unless it was already started, serves until ctx is done and then closes the server,
+// waiting for outstanding requests to complete. It is meant for embedding the mock in long-running harnesses.
+//
+//	go s.ServeUntil(ctx)
+//	<-s.Ready()
+func (s *Server) ServeUntil(ctx context.Context) {
+	select {
+	case <-s.ready:
+	default:
+		s.Start()
+	}
+	<-ctx.Done()
+	s.Close()
+}
+
+// Close shuts down a started server. With the DeferFailures option, it then reports the failures collected.
 func (s *Server) Close() {
 	s.httpServer.Close()
+	if s.deferred != nil {
+		s.reportDeferred(s.deferred.t)
+	}
+}
+
+// Reset clears the server's recorded history, latencies, request counts and metrics, the state of its handler if it
+// has a Reset method, like the mock handlers (whose expectations and calls are cleared) and the stateful handlers of
+// this package, and the state of its stateful middleware such as the budgets of Quota and the schedule of Degrade,
+// which start over with the next request. It lets table-driven subtests reuse one server instead of starting one per case. It must not be called
+// while requests are being handled.
+func (s *Server) Reset() {
+	if r, ok := s.handler.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+	s.history.reset()
+	s.latencies.reset()
+	s.requests.reset()
+	s.metrics.reset()
+	atomic.AddInt64(&s.resets, 1)
+}
+
+// HTTPHandler returns the http.Handler that turns requests into calls to the server's Handler, applying all options.
+// It makes it possible to serve the mock with something other than the built-in httptest server, such as the HTTP/3
+// server of the http3mock module, alongside it or with the server left unstarted. Close the server either way.
+func (s *Server) HTTPHandler() http.Handler {
+	return s.httpServer.Config.Handler
+}
+
+// Setenv sets the environment variable key to the server's URL for the duration of the test, which is handy when the
+// code under test reads its downstream's address from the environment.
+func (s *Server) Setenv(t testing.TB, key string) {
+	t.Setenv(key, s.URL())
+}
+
+// Env returns "key=URL" for the server's URL, for use in the Env of an exec.Cmd that launches the binary under test.
+//
+//	cmd := exec.Command("./my-service")
+//	cmd.Env = append(os.Environ(), s.Env("DOWNSTREAM_URL"))
+func (s *Server) Env(key string) string {
+	return key + "=" + s.URL()
+}
+
+// reportError reports an error that happened while serving a request, either to the test set with ReportErrorsTo or
+// to the Logger.
+func (s *Server) reportError(format string, args ...interface{}) {
+	if s.reportErrorsTo != nil {
+		s.reportErrorsTo.Errorf("httpmock: "+format, args...)
+		return
+	}
+	s.log().Error(fmt.Sprintf("httpmock: "+format, args...))
 }
 
 // URL is the URL for the local test server, i.e. the value of httptest.Server.URL
@@ -153,21 +353,77 @@ func (s *Server) URL() string {
 // httpToHTTPMockHandler is a normal http.Handler that converts the request into a httpmock.Handler call and calls the
 // httmock handler.
 type httpToHTTPMockHandler struct {
-	handler            Handler
-	handlerWithHeaders HandlerWithHeaders
+	server *Server
 }
 
 // ServeHTTP makes this implement http.Handler
 func (h *httpToHTTPMockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Taken first, as the connection only starts recording the next request's header names once they are and the body
+	// has been read
+	rawNames := rawHeaderNames(r)
+	id := h.server.inflight.add(RequestInfo{Method: r.Method, Path: r.URL.RequestURI(), Header: r.Header, Proto: r.Proto})
+	defer h.server.inflight.remove(id)
+	if h.server.serveMetrics(w, r) || h.server.ignore(w, r) {
+		return
+	}
+	h.server.requests.add()
+
+	if h.server.latencies != nil {
+		start := time.Now()
+		defer func() { h.server.latencies.record(r.Method+" "+r.URL.Path, time.Since(start)) }()
+	}
+
+	if h.server.failRead(w, r) {
+		return
+	}
 	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		log.Printf("Failed to read HTTP body in httpmock: %v", err)
+	req := RequestInfo{
+		Method:         r.Method,
+		Path:           r.URL.RequestURI(),
+		Host:           r.Host,
+		Header:         r.Header,
+		Body:           body,
+		Proto:          r.Proto,
+		RawHeaderNames: rawNames,
+		TLS:            r.TLS,
+		ConnID:         connID(r),
+		RemoteAddr:     r.RemoteAddr,
+		LocalAddr:      localAddr(r),
+		BodyErr:        err,
+		resets:         atomic.LoadInt64(&h.server.resets),
+	}
+	if err != nil && !h.server.handleBodyError(req) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 	var resp Response
-	if h.handler != nil {
-		resp = h.handler.Handle(r.Method, r.URL.RequestURI(), body)
-	} else {
-		resp = h.handlerWithHeaders.HandleWithHeaders(r.Method, r.URL.RequestURI(), r.Header, body)
+	if h.server.history != nil {
+		i := h.server.history.start(req)
+		req.seq = i + 1
+		defer func() { h.server.history.end(i, resp) }()
+	}
+	if h.server.metrics != nil {
+		start := time.Now()
+		defer func() { h.server.metrics.record(r.Method, r.URL.Path, responseStatus(resp), time.Since(start)) }()
+	}
+	resp = h.serveRecovered(req)
+
+	if resp.Hijack != nil {
+		if err := hijack(w, resp.Hijack); err != nil {
+			h.server.reportError("failed to hijack connection for %s %s: %v", r.Method, r.URL, err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if resp.BodyObject != nil {
+		data, err := json.Marshal(resp.BodyObject)
+		if err != nil {
+			h.server.reportError("failed to marshal BodyObject %v for %s %s: %v", resp.BodyObject, r.Method, r.URL, err)
+			resp = Response{Status: http.StatusInternalServerError}
+		} else {
+			resp.Body = data
+		}
 	}
 
 	for k, v := range resp.Header {
@@ -175,14 +431,61 @@ func (h *httpToHTTPMockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 			w.Header().Add(k, val)
 		}
 	}
+	if resp.BodyObject != nil && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
 
 	status := resp.Status
 	if status == 0 {
 		status = 200
 	}
+	if resp.Reason != "" {
+		err = writeRawResponse(w, r, status, resp.Reason, resp.Body)
+		if err == nil {
+			return
+		}
+		h.server.reportError("failed to write %d %s response for %s %s: %v", status, resp.Reason, r.Method, r.URL, err)
+	}
 	w.WriteHeader(status)
 	_, err = w.Write(resp.Body)
 	if err != nil {
-		log.Printf("Failed to write response in httpmock: %v", err)
+		h.server.log().Warn("httpmock: failed to write response", "method", r.Method, "path", r.URL.RequestURI(),
+			"error", err)
+	}
+}
+
+// serveRecovered serves req like serve, but turns a panic of the handler or middleware into a 500 response and reports
+// it, rather than letting net/http log it and drop the connection.
+func (h *httpToHTTPMockHandler) serveRecovered(req RequestInfo) (resp Response) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+		if value == http.ErrAbortHandler {
+			// Deliberately aborting the response, leave it to net/http
+			panic(value)
+		}
+		stack := debug.Stack()
+		if h.server.onPanic != nil {
+			h.server.onPanic(req, value, stack)
+		} else {
+			h.server.reportError("handler panicked on %s %s: %v\n%s", req.Method, req.Path, value, stack)
+		}
+		resp = Response{
+			Status: http.StatusInternalServerError,
+			Body:   []byte(fmt.Sprintf("httpmock: handler panicked: %v", value)),
+		}
+	}()
+	return h.serve(req, h.server.middleware)
+}
+
+// serve passes req through the given middleware and then to the httpmock handler, returning its response.
+func (h *httpToHTTPMockHandler) serve(req RequestInfo, middleware []Middleware) Response {
+	if len(middleware) > 0 {
+		return middleware[0](req, func(req RequestInfo) Response {
+			return h.serve(req, middleware[1:])
+		})
 	}
+	return ServeRequest(h.server.handler, req)
 }
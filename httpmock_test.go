@@ -1,10 +1,15 @@
 package httpmock
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -103,3 +108,292 @@ func TestMultiHeaderMatcher(t *testing.T) {
 
 	downstream.AssertExpectations(t)
 }
+
+func TestBasicRequestResponseWithRequest(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+
+	downstream.On("HandleRequest", RequestMatcher(func(req RequestInfo) bool {
+		return req.Method == "POST" && req.Path == "/object/12345?x=1" &&
+			req.Header.Get("HTTPMOCK-TEST") == "its here" && string(req.Body) == "hello"
+	})).Return(Response{
+		Body: []byte(`{"status": "ok"}`),
+	})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/object/12345?x=1", s.URL()), strings.NewReader("hello"))
+	require.NoError(t, err)
+	req.Header.Set("HTTPMOCK-TEST", "its here")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"status": "ok"}`), body)
+
+	downstream.AssertExpectations(t)
+}
+
+func TestTypedHandler(t *testing.T) {
+	type order struct {
+		ID string `json:"id"`
+	}
+	type receipt struct {
+		OrderID string `json:"order_id"`
+		Path    string `json:"path"`
+	}
+
+	s := NewServer(NewTypedHandler(func(method, path string, o order) receipt {
+		return receipt{OrderID: o.ID, Path: path}
+	}))
+	defer s.Close()
+
+	resp, err := http.Post(fmt.Sprintf("%s/orders", s.URL()), "application/json", strings.NewReader(`{"id":"a1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"order_id":"a1","path":"/orders"}`, string(body))
+
+	resp, err = http.Post(fmt.Sprintf("%s/orders", s.URL()), "application/json", strings.NewReader(`not json`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+type recordingT struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Errors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errors...)
+}
+
+func TestBodyObject(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/object/12345", mock.Anything).Return(Response{
+		BodyObject: map[string]string{"status": "ok"},
+	})
+	downstream.On("Handle", "GET", "/broken", mock.Anything).Return(Response{
+		BodyObject: func() {},
+	})
+
+	reporter := &recordingT{}
+	s := NewServer(downstream, ReportErrorsTo(reporter))
+	defer s.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%s/object/12345", s.URL()))
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status": "ok"}`, string(body))
+	assert.Empty(t, reporter.Errors())
+
+	resp, err = http.Get(fmt.Sprintf("%s/broken", s.URL()))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Len(t, reporter.Errors(), 1)
+
+	downstream.AssertExpectations(t)
+}
+
+func TestHTTPHandlerProto(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+	protos := &Captor[RequestInfo]{}
+	downstream.On("HandleRequest", protos.Matcher()).Return(Response{})
+
+	s := NewUnstartedServer(downstream)
+	defer s.Close()
+
+	// Serve the mock over HTTP/2 with a separate server, the way a QUIC server would serve it over HTTP/3
+	h2 := httptest.NewUnstartedServer(s.HTTPHandler())
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+
+	_, err := h2.Client().Get(h2.URL + "/proto")
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", protos.Last().Proto)
+
+	downstream.AssertExpectations(t)
+}
+
+func TestServeUntil(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{})
+
+	s := NewUnstartedServer(downstream)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.ServeUntil(ctx)
+		close(done)
+	}()
+	<-s.Ready()
+
+	resp, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	cancel()
+	<-done
+	_, err = http.Get(s.URL() + "/")
+	assert.Error(t, err)
+
+	downstream.AssertExpectations(t)
+}
+
+func TestReset(t *testing.T) {
+	downstream := NewMockHandler(t)
+	s := NewServer(downstream, RecordHistory())
+	defer s.Close()
+
+	for _, status := range []int{201, 202} {
+		s.Reset()
+		downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{Status: status})
+
+		resp, err := http.Get(s.URL() + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, status, resp.StatusCode)
+		assert.Len(t, s.History(), 1)
+		downstream.AssertExpectations(t)
+	}
+}
+
+func TestAssertHandleCalled(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", mock.Anything, mock.Anything).Return(Response{})
+	s := NewServer(downstream)
+	defer s.Close()
+
+	for _, path := range []string{"/object/12345", "/object/12345", "/object/6789"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.True(t, downstream.AssertHandleCalled(t, "GET", "/object/12345", 2))
+	assert.True(t, downstream.AssertHandleCalled(t, "GET", "/object/6789", 1))
+	assert.True(t, downstream.AssertHandleCalled(t, "POST", "/object/12345", 0))
+
+	rt := &recordingT{}
+	assert.False(t, downstream.AssertHandleCalled(rt, "GET", "/object/6789", 2))
+	assert.Equal(t, []string{"httpmock: expected GET /object/6789 to be handled 2 times, but it was handled 1 times"},
+		rt.Errors())
+
+	withRequest := &MockHandlerWithRequest{}
+	withRequest.On("HandleRequest", mock.Anything).Return(Response{})
+	ServeRequest(withRequest, RequestInfo{Method: "PUT", Path: "/a"})
+	assert.True(t, withRequest.AssertHandleCalled(t, "PUT", "/a", 1))
+}
+
+func TestAssertHandleCalledInFlight(t *testing.T) {
+	release := make(chan time.Time)
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/slow", mock.Anything).WaitUntil(release).Return(Response{})
+	s := NewServer(downstream)
+	defer s.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if resp, err := http.Get(s.URL() + "/slow"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	assert.Eventually(t, func() bool { return len(s.inflight.list()) == 1 }, time.Second, time.Millisecond)
+	assert.True(t, downstream.AssertHandleCalled(t, "GET", "/slow", 0))
+	close(release)
+	<-done
+	assert.True(t, downstream.AssertHandleCalled(t, "GET", "/slow", 1))
+}
+
+func TestPanicRecovery(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/panic", mock.Anything).Run(func(mock.Arguments) {
+		panic("boom")
+	}).Return(Response{})
+
+	rt := &recordingT{}
+	s := NewServer(downstream, ReportErrorsTo(rt))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/panic")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "httpmock: handler panicked: boom", string(body))
+	require.Len(t, rt.Errors(), 1)
+	assert.Contains(t, rt.Errors()[0], "httpmock: handler panicked on GET /panic: boom\n")
+	assert.Contains(t, rt.Errors()[0], "goroutine")
+
+	var panicked []string
+	s2 := NewServer(downstream, OnPanic(func(req RequestInfo, value interface{}, stack []byte) {
+		panicked = append(panicked, fmt.Sprintf("%s %s: %v", req.Method, req.Path, value))
+	}))
+	defer s2.Close()
+
+	resp, err = http.Get(s2.URL() + "/panic")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, []string{"GET /panic: boom"}, panicked)
+}
+
+func TestNewMockHandlerT(t *testing.T) {
+	var downstream *MockHandlerWithHeaders
+	t.Run("asserted on cleanup", func(t *testing.T) {
+		downstream = NewMockHandlerWithHeadersT(t)
+		downstream.On("HandleWithHeaders", "GET", "/", mock.Anything, mock.Anything).Return(Response{})
+		s := NewServer(downstream)
+		defer s.Close()
+
+		resp, err := http.Get(s.URL() + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+	downstream.AssertExpectations(t)
+}
+
+func TestRecordHistoryWithLimits(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "POST", "/", mock.Anything).Return(Response{Body: []byte("0123456789")})
+	s := NewServer(downstream, RecordHistoryWithLimits(HistoryLimits{MaxBodies: 2, MaxBodyBytes: 8, MaxTotalBytes: 20}))
+	defer s.Close()
+
+	for _, body := range []string{"abc", "abcdefghij", "xyz"} {
+		resp, err := http.Post(s.URL()+"/", "text/plain", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	history := s.History()
+	require.Len(t, history, 3)
+	// Dropped to keep 2 requests' bodies, and within 20 bytes
+	assert.Nil(t, history[0].Body)
+	assert.Nil(t, history[0].Response.Body)
+	assert.True(t, history[0].BodyTruncated)
+	assert.True(t, history[0].ResponseBodyTruncated)
+	// Dropped to stay within 20 bytes: 8+8 from the second request and 3+8 from the third
+	assert.Nil(t, history[1].Body)
+	assert.True(t, history[1].BodyTruncated)
+	// Cut short to 8 bytes
+	assert.Equal(t, "xyz", string(history[2].Body))
+	assert.False(t, history[2].BodyTruncated)
+	assert.Equal(t, "01234567", string(history[2].Response.Body))
+	assert.True(t, history[2].ResponseBodyTruncated)
+}
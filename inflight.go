@@ -0,0 +1,124 @@
+package httpmock
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CloseGracefully shuts down a started server like Close, but first stops accepting connections and waits up to timeout
+// for the requests being handled to complete, to avoid "connection reset" errors at test teardown. It returns the
+// requests that were still being handled when the timeout expired; their connections are then closed without waiting
+// for their handlers to return.
+//
+//	assert.Empty(t, s.CloseGracefully(time.Second))
+func (s *Server) CloseGracefully(timeout time.Duration) []RecordedRequest {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if s.deferred != nil {
+		defer s.reportDeferred(s.deferred.t)
+	}
+	if err := s.httpServer.Config.Shutdown(ctx); err == nil {
+		s.httpServer.Close()
+		return nil
+	}
+
+	running := s.inflight.list()
+	s.httpServer.CloseClientConnections()
+	// Close waits for the handlers to return, which is what the timeout is meant to avoid
+	go s.httpServer.Close()
+	return running
+}
+
+// AssertIdle asserts that the server is not handling any requests and has no connections open other than idle
+// keep-alive ones, which clients normally pool. Calling it at the end of a test catches background goroutines that are
+// still talking to the mock.
+func (s *Server) AssertIdle(t TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ok := true
+	for _, req := range s.inflight.list() {
+		t.Errorf("httpmock: %s %s is still being handled, since %s", req.Method, req.Path, time.Since(req.Start))
+		ok = false
+	}
+	if n := s.inflight.busyConns(); n > 0 {
+		t.Errorf("httpmock: %d connections are still open and not idle", n)
+		ok = false
+	}
+	return ok
+}
+
+// inflight tracks the requests a server is currently handling, and the state of its connections.
+type inflight struct {
+	mu       sync.Mutex
+	next     int
+	requests map[int]RecordedRequest
+	conns    map[net.Conn]http.ConnState
+}
+
+func newInflight() *inflight {
+	return &inflight{requests: map[int]RecordedRequest{}, conns: map[net.Conn]http.ConnState{}}
+}
+
+// connState tracks connection states, for use as http.Server.ConnState.
+func (f *inflight) connState(c net.Conn, state http.ConnState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		delete(f.conns, c)
+	default:
+		f.conns[c] = state
+	}
+}
+
+// busyConns returns the number of open connections that aren't idle.
+func (f *inflight) busyConns() int {
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, state := range f.conns {
+		if state != http.StateIdle {
+			n++
+		}
+	}
+	return n
+}
+
+// add records that req started being handled, returning an id for remove.
+func (f *inflight) add(req RequestInfo) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	f.requests[f.next] = RecordedRequest{RequestInfo: req, Start: time.Now()}
+	return f.next
+}
+
+// remove records that the request with the given id is done.
+func (f *inflight) remove(id int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.requests, id)
+}
+
+// list returns the requests being handled, in the order they arrived.
+func (f *inflight) list() []RecordedRequest {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var requests []RecordedRequest
+	for _, req := range f.requests {
+		requests = append(requests, req)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].Start.Before(requests[j].Start) })
+	return requests
+}
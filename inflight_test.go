@@ -0,0 +1,66 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseGracefully(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/fast", mock.Anything).Return(Response{})
+	downstream.On("Handle", "GET", "/slow", mock.Anything).Return(Response{}).After(time.Second)
+
+	s := NewServer(downstream)
+	resp, err := http.Get(s.URL() + "/fast")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, s.CloseGracefully(time.Second))
+
+	s = NewServer(downstream)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := http.Get(s.URL() + "/slow")
+		errs <- err
+	}()
+	require.Eventually(t, func() bool { return len(s.inflight.list()) == 1 }, time.Second, time.Millisecond)
+
+	running := s.CloseGracefully(50 * time.Millisecond)
+	require.Len(t, running, 1)
+	assert.Equal(t, "/slow", running[0].Path)
+	assert.Error(t, <-errs)
+}
+
+func TestAssertIdle(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/fast", mock.Anything).Return(Response{})
+	downstream.On("Handle", "GET", "/slow", mock.Anything).Return(Response{}).After(200 * time.Millisecond)
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/fast")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Eventually(t, func() bool { return s.inflight.busyConns() == 0 }, time.Second, time.Millisecond)
+	assert.True(t, s.AssertIdle(t))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Get(s.URL() + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	require.Eventually(t, func() bool { return len(s.inflight.list()) == 1 }, time.Second, time.Millisecond)
+
+	reporter := &recordingT{}
+	assert.False(t, s.AssertIdle(reporter))
+	assert.Len(t, reporter.Errors(), 2)
+	<-done
+}
@@ -0,0 +1,225 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// JSONOption relaxes how JSONMatcherWith compares the argument to the expected object.
+type JSONOption func(c *jsonComparison)
+
+// jsonComparison compares decoded JSON values, with the relaxations set by JSONOptions.
+type jsonComparison struct {
+	timeTolerance time.Duration
+	anyTime       bool
+	absEpsilon    float64
+	relEpsilon    float64
+	rules         map[string]FieldRule
+}
+
+// TimestampTolerance returns a JSONOption making RFC3339 timestamp strings match if they are within d of each other,
+// e.g. for a "created_at" field set by the client from its own clock.
+func TimestampTolerance(d time.Duration) JSONOption {
+	return func(c *jsonComparison) {
+		c.timeTolerance = d
+	}
+}
+
+// AnyTimestamp returns a JSONOption making any valid RFC3339 timestamp string match an expected timestamp, whatever
+// its value. The expected field can be left to its zero value, e.g. time.Time{}.
+func AnyTimestamp() JSONOption {
+	return func(c *jsonComparison) {
+		c.anyTime = true
+	}
+}
+
+// FloatTolerance returns a JSONOption making numbers match if they differ by at most abs, or by at most rel times the
+// larger of their magnitudes, e.g. for values computed by the client in floating point that may differ in the last
+// bits. Either epsilon can be zero to only use the other one.
+func FloatTolerance(abs, rel float64) JSONOption {
+	return func(c *jsonComparison) {
+		c.absEpsilon = abs
+		c.relEpsilon = rel
+	}
+}
+
+// FieldRule checks the decoded JSON value of a field, in place of comparing it to the expected value: a string,
+// float64, bool, nil, []interface{} or map[string]interface{}.
+type FieldRule func(value interface{}) bool
+
+// Rules maps fields to the rules checking them, for JSONMatcherWithRules. Fields are given by their dotted path from
+// the top-level object, e.g. "id" or "items.0.id", where a "*" element matches any key or index, e.g. "items.*.id".
+type Rules map[string]FieldRule
+
+// WithRules returns a JSONOption checking the given fields with their rules, whatever their expected values.
+func WithRules(rules Rules) JSONOption {
+	return func(c *jsonComparison) {
+		if c.rules == nil {
+			c.rules = make(map[string]FieldRule)
+		}
+		for path, rule := range rules {
+			c.rules[path] = rule
+		}
+	}
+}
+
+// JSONMatcherWithRules returns a mock.MatchedBy func to check if the argument is the json form of the provided object,
+// like JSONMatcherWith, except for the fields of rules which are checked by their rule instead, e.g. for identifiers
+// generated by the client. The expected object's values for these fields are ignored, but the fields must be present.
+//
+//	downstream.On("Handle", "POST", "/orders", httpmock.JSONMatcherWithRules(
+//		Order{Item: "book"}, httpmock.Rules{"id": httpmock.IsUUID, "lines.*.id": httpmock.IsNonEmptyString}))
+func JSONMatcherWithRules(o1 interface{}, rules Rules, opts ...JSONOption) interface{} {
+	return JSONMatcherWith(o1, append(opts, WithRules(rules))...)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID is a FieldRule matching UUID strings in their canonical form, e.g. "6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+func IsUUID(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && uuidPattern.MatchString(s)
+}
+
+// IsNonEmptyString is a FieldRule matching any string but "".
+func IsNonEmptyString(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && s != ""
+}
+
+// MatchesRegexp returns a FieldRule matching strings that contain a match of the regular expression pattern. It
+// panics if pattern doesn't compile.
+func MatchesRegexp(pattern string) FieldRule {
+	re := regexp.MustCompile(pattern)
+	return func(value interface{}) bool {
+		s, ok := value.(string)
+		return ok && re.MatchString(s)
+	}
+}
+
+// JSONMatcherWith returns a mock.MatchedBy func to check if the argument is the json form of the provided object,
+// like JSONMatcher, relaxed by the given options for fields whose exact values the test can't predict.
+//
+//	downstream.On("Handle", "POST", "/events", httpmock.JSONMatcherWith(
+//		Event{Name: "signup", CreatedAt: time.Now()}, httpmock.TimestampTolerance(time.Minute)))
+func JSONMatcherWith(o1 interface{}, opts ...JSONOption) interface{} {
+	c := &jsonComparison{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	var expected interface{}
+	if err := json.Unmarshal(ToJSON(o1), &expected); err != nil {
+		panic("httpmock: JSONMatcherWith: " + err.Error())
+	}
+
+	return mock.MatchedBy(func(arg []byte) bool {
+		// Decode into the expected object's type first, so that the same fields are compared as by JSONMatcher
+		o2 := reflect.New(reflect.Indirect(reflect.ValueOf(o1)).Type()).Interface()
+		if err := json.Unmarshal(arg, o2); err != nil {
+			return false
+		}
+		var actual interface{}
+		if err := json.Unmarshal(ToJSON(o2), &actual); err != nil {
+			return false
+		}
+		return c.equal(nil, expected, actual)
+	})
+}
+
+// equal reports whether the decoded JSON values at path match.
+func (c *jsonComparison) equal(path []string, expected, actual interface{}) bool {
+	if rule := c.rule(path); rule != nil {
+		return rule(actual)
+	}
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for k, v := range e {
+			av, ok := a[k]
+			if !ok || !c.equal(append(path, k), v, av) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !c.equal(append(path, strconv.Itoa(i)), e[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		return a == e || c.timestampsMatch(e, a)
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		diff := math.Abs(a - e)
+		return diff <= c.absEpsilon || diff <= c.relEpsilon*math.Max(math.Abs(a), math.Abs(e))
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// rule returns the rule for the field at path, if any.
+func (c *jsonComparison) rule(path []string) FieldRule {
+	if len(path) == 0 {
+		return nil
+	}
+	for pattern, rule := range c.rules {
+		elems := strings.Split(pattern, ".")
+		if len(elems) != len(path) {
+			continue
+		}
+		matched := true
+		for i, elem := range elems {
+			if elem != "*" && elem != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule
+		}
+	}
+	return nil
+}
+
+// timestampsMatch reports whether expected and actual are RFC3339 timestamps matching under the options.
+func (c *jsonComparison) timestampsMatch(expected, actual string) bool {
+	if !c.anyTime && c.timeTolerance <= 0 {
+		return false
+	}
+	et, err := time.Parse(time.RFC3339, expected)
+	if err != nil {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, actual)
+	if err != nil {
+		return false
+	}
+	if c.anyTime {
+		return true
+	}
+	diff := at.Sub(et)
+	return diff <= c.timeTolerance && diff >= -c.timeTolerance
+}
@@ -0,0 +1,73 @@
+package httpmock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonEvent struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestJSONMatcherWithTimestamps(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	body := []byte(`{"name": "signup", "created_at": "2024-01-02T03:04:35+00:00"}`)
+
+	assert.False(t, matches(JSONMatcherWith(jsonEvent{"signup", now}), body))
+	assert.True(t, matches(JSONMatcherWith(jsonEvent{"signup", now}, TimestampTolerance(time.Minute)), body))
+	assert.False(t, matches(JSONMatcherWith(jsonEvent{"signup", now}, TimestampTolerance(time.Second)), body))
+	assert.False(t, matches(JSONMatcherWith(jsonEvent{"login", now}, TimestampTolerance(time.Minute)), body))
+
+	assert.True(t, matches(JSONMatcherWith(jsonEvent{Name: "signup"}, AnyTimestamp()), body))
+	assert.False(t, matches(JSONMatcherWith(jsonEvent{Name: "signup"}, AnyTimestamp()),
+		[]byte(`{"name": "signup", "created_at": "yesterday"}`)))
+
+	// Generic objects are compared field by field
+	expected := map[string]interface{}{"at": "2024-01-02T03:04:05Z", "tags": []string{"a"}}
+	assert.True(t, matches(JSONMatcherWith(expected, TimestampTolerance(time.Minute)),
+		[]byte(`{"at": "2024-01-02T03:04:06.5Z", "tags": ["a"]}`)))
+	assert.False(t, matches(JSONMatcherWith(expected, TimestampTolerance(time.Minute)),
+		[]byte(`{"at": "2024-01-02T03:04:06Z", "tags": ["a"], "extra": 1}`)))
+}
+
+func TestJSONMatcherWithFloatTolerance(t *testing.T) {
+	expected := map[string]float64{"total": 0.3}
+	body := []byte(`{"total": 0.30000000000000004}`)
+
+	assert.False(t, matches(JSONMatcherWith(expected), body))
+	assert.True(t, matches(JSONMatcherWith(expected, FloatTolerance(1e-9, 0)), body))
+	assert.True(t, matches(JSONMatcherWith(expected, FloatTolerance(0, 1e-9)), body))
+	assert.False(t, matches(JSONMatcherWith(expected, FloatTolerance(1e-9, 0)), []byte(`{"total": 0.31}`)))
+	assert.True(t, matches(JSONMatcherWith(expected, FloatTolerance(0, 0.05)), []byte(`{"total": 0.31}`)))
+	assert.True(t, matches(JSONMatcherWith(map[string]float64{"big": 1e12}, FloatTolerance(0, 1e-9)),
+		[]byte(`{"big": 1000000000000.5}`)))
+}
+
+func TestJSONMatcherWithRules(t *testing.T) {
+	type line struct {
+		ID  string `json:"id"`
+		Qty int    `json:"qty"`
+	}
+	type order struct {
+		ID    string `json:"id"`
+		Ref   string `json:"ref"`
+		Lines []line `json:"lines"`
+	}
+	matcher := JSONMatcherWithRules(order{Lines: []line{{Qty: 1}, {Qty: 2}}}, Rules{
+		"id":         IsUUID,
+		"ref":        MatchesRegexp(`^ORD-\d+$`),
+		"lines.*.id": IsNonEmptyString,
+	})
+
+	assert.True(t, matches(matcher, []byte(`{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "ref": "ORD-12",
+		"lines": [{"id": "a", "qty": 1}, {"id": "b", "qty": 2}]}`)))
+	assert.False(t, matches(matcher, []byte(`{"id": "not-a-uuid", "ref": "ORD-12",
+		"lines": [{"id": "a", "qty": 1}, {"id": "b", "qty": 2}]}`)))
+	assert.False(t, matches(matcher, []byte(`{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "ref": "ORD-12",
+		"lines": [{"id": "a", "qty": 1}, {"qty": 2}]}`)))
+	assert.False(t, matches(matcher, []byte(`{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "ref": "ORD-12",
+		"lines": [{"id": "a", "qty": 1}, {"id": "b", "qty": 3}]}`)))
+}
@@ -0,0 +1,78 @@
+package httpmock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyStats summarizes how long a Server took to serve the requests to one route.
+type LatencyStats struct {
+	Count         int
+	P50, P95, P99 time.Duration
+	Max           time.Duration
+}
+
+// RecordLatencies makes the server record how long it takes to serve each request, see Server.LatencyStats. Only
+// durations are kept, so this is cheap enough for load-oriented tests.
+func RecordLatencies() Option {
+	return func(s *Server) {
+		s.latencies = &latencies{byRoute: make(map[string][]time.Duration)}
+	}
+}
+
+// LatencyStats returns latency statistics per route, keyed by method and path without the query string, e.g.
+// "GET /object/12345". Latencies are measured from when the request headers were read until the response was
+// written. It returns nil unless the server was created with the RecordLatencies option.
+func (s *Server) LatencyStats() map[string]LatencyStats {
+	if s.latencies == nil {
+		return nil
+	}
+	s.latencies.mu.Lock()
+	defer s.latencies.mu.Unlock()
+
+	stats := make(map[string]LatencyStats, len(s.latencies.byRoute))
+	for route, durations := range s.latencies.byRoute {
+		sorted := append([]time.Duration(nil), durations...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats[route] = LatencyStats{
+			Count: len(sorted),
+			P50:   percentile(sorted, 50),
+			P95:   percentile(sorted, 95),
+			P99:   percentile(sorted, 99),
+			Max:   sorted[len(sorted)-1],
+		}
+	}
+	return stats
+}
+
+// percentile returns the p-th percentile of sorted durations using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// latencies holds the durations recorded by a server with the RecordLatencies option.
+type latencies struct {
+	mu      sync.Mutex
+	byRoute map[string][]time.Duration
+}
+
+// reset forgets all recorded durations.
+func (l *latencies) reset() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.byRoute = map[string][]time.Duration{}
+}
+
+func (l *latencies) record(route string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.byRoute[route] = append(l.byRoute[route], d)
+}
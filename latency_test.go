@@ -0,0 +1,46 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyStats(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", mock.Anything, mock.Anything).Return(Response{})
+
+	s := NewServer(downstream, RecordLatencies())
+	defer s.Close()
+
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get(s.URL() + "/object/12345?attempt=1")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	resp, err := http.Get(s.URL() + "/other")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	stats := s.LatencyStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, 10, stats["GET /object/12345"].Count)
+	assert.Equal(t, 1, stats["GET /other"].Count)
+	assert.LessOrEqual(t, stats["GET /object/12345"].P50, stats["GET /object/12345"].P99)
+	assert.LessOrEqual(t, stats["GET /object/12345"].P99, stats["GET /object/12345"].Max)
+	assert.Greater(t, stats["GET /other"].Max, time.Duration(0))
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	assert.Equal(t, time.Duration(50), percentile(sorted, 50))
+	assert.Equal(t, time.Duration(99), percentile(sorted, 99))
+	assert.Equal(t, time.Duration(1), percentile(sorted[:1], 99))
+}
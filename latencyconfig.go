@@ -0,0 +1,157 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LatencyConfig declares latency profiles per path, for modeling a realistic downstream in performance test
+// environments without writing code. It is typically loaded from a JSON file with LoadLatencyConfig:
+//
+//	{
+//	  "profiles": [
+//	    {"method": "GET", "path": "/orders/*", "type": "fixed", "delay": "50ms"},
+//	    {"path": "/search", "type": "lognormal", "median": "20ms", "sigma": 0.5, "seed": 1},
+//	    {"path": "/reports/**", "type": "ramp", "from": "10ms", "to": "2s", "over": "5m"}
+//	  ]
+//	}
+type LatencyConfig struct {
+	// The profiles, the first one matching a request applying to it
+	Profiles []LatencyProfile `json:"profiles"`
+}
+
+// LatencyProfile is the latency of the requests to one route, see LatencyConfig. Depending on its Type, it delays
+// responses by:
+//
+//   - "fixed": Delay
+//   - "uniform": a delay drawn uniformly between Min and Max, see UniformDelay
+//   - "normal": a delay drawn from a normal distribution, see NormalDelay
+//   - "lognormal": a delay drawn from a log-normal distribution, see LogNormalDelay
+//   - "ramp": a delay growing linearly from From to To over Over, see RampDelay
+type LatencyProfile struct {
+	// The method of the requests the profile applies to, any if empty
+	Method string `json:"method,omitempty"`
+	// A glob pattern matching the path of the requests the profile applies to, see PathGlobMatcher
+	Path string `json:"path"`
+	Type string `json:"type"`
+
+	Delay  Duration `json:"delay,omitempty"`
+	Min    Duration `json:"min,omitempty"`
+	Max    Duration `json:"max,omitempty"`
+	Mean   Duration `json:"mean,omitempty"`
+	StdDev Duration `json:"stddev,omitempty"`
+	Median Duration `json:"median,omitempty"`
+	Sigma  float64  `json:"sigma,omitempty"`
+	From   Duration `json:"from,omitempty"`
+	To     Duration `json:"to,omitempty"`
+	Over   Duration `json:"over,omitempty"`
+	// The seed of the random distributions, which draw the same delays on every run
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Duration is a time.Duration read from and written to JSON as a string like "150ms", see time.ParseDuration. A
+// number is read as nanoseconds.
+type Duration time.Duration
+
+// MarshalJSON makes this implement json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON makes this implement json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var ns int64
+		if err := json.Unmarshal(data, &ns); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(ns)
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadLatencyConfig reads a LatencyConfig from a JSON file, checking that its profiles are valid.
+func LoadLatencyConfig(filename string) (LatencyConfig, error) {
+	var c LatencyConfig
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	return c, c.Validate()
+}
+
+// Validate checks that the profiles are valid.
+func (c LatencyConfig) Validate() error {
+	for i, p := range c.Profiles {
+		if _, err := p.Distribution(); err != nil {
+			return fmt.Errorf("latency profile %d (%s): %v", i, p.Path, err)
+		}
+	}
+	return nil
+}
+
+// Distribution returns the Distribution of the profile's delays.
+func (p LatencyProfile) Distribution() (Distribution, error) {
+	switch p.Type {
+	case "fixed":
+		return FixedDelay(time.Duration(p.Delay)), nil
+	case "uniform":
+		if p.Max < p.Min {
+			return nil, fmt.Errorf("max %v is less than min %v", time.Duration(p.Max), time.Duration(p.Min))
+		}
+		return UniformDelay(time.Duration(p.Min), time.Duration(p.Max), p.Seed), nil
+	case "normal":
+		return NormalDelay(time.Duration(p.Mean), time.Duration(p.StdDev), p.Seed), nil
+	case "lognormal":
+		return LogNormalDelay(time.Duration(p.Median), p.Sigma, p.Seed), nil
+	case "ramp":
+		return RampDelay(time.Duration(p.From), time.Duration(p.To), time.Duration(p.Over), nil), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", p.Type)
+	}
+}
+
+// WithLatencyConfig makes the server delay responses according to the first profile of c matching each request,
+// before the handler is called; requests matching no profile aren't delayed. It panics if c is invalid, see
+// LatencyConfig.Validate.
+//
+//	config, err := httpmock.LoadLatencyConfig("testdata/latency.json")
+//	require.NoError(t, err)
+//	s := httpmock.NewServer(downstream, httpmock.WithLatencyConfig(config))
+func WithLatencyConfig(c LatencyConfig) Option {
+	type route struct {
+		method  string
+		pattern []string
+		dist    Distribution
+	}
+	routes := make([]route, len(c.Profiles))
+	for i, p := range c.Profiles {
+		dist, err := p.Distribution()
+		if err != nil {
+			panic(fmt.Sprintf("httpmock: latency profile %d (%s): %v", i, p.Path, err))
+		}
+		routes[i] = route{method: p.Method, pattern: splitPath(p.Path), dist: dist}
+	}
+	return WithMiddleware(func(req RequestInfo, next func(RequestInfo) Response) Response {
+		segments := splitPath(stripQuery(req.Path))
+		for _, r := range routes {
+			if (r.method == "" || r.method == req.Method) && globMatch(r.pattern, segments) {
+				time.Sleep(r.dist.Sample())
+				break
+			}
+		}
+		return next(req)
+	})
+}
@@ -0,0 +1,57 @@
+package httpmock
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLatencyConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "latency.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"profiles": [
+		{"method": "GET", "path": "/orders/*", "type": "fixed", "delay": "50ms"},
+		{"path": "/search", "type": "uniform", "min": "10ms", "max": 20000000, "seed": 1},
+		{"path": "/reports/**", "type": "ramp", "from": "10ms", "to": "2s", "over": "5m"}
+	]}`), 0644))
+
+	c, err := LoadLatencyConfig(filename)
+	require.NoError(t, err)
+	require.Len(t, c.Profiles, 3)
+	assert.Equal(t, Duration(50*time.Millisecond), c.Profiles[0].Delay)
+	assert.Equal(t, Duration(20*time.Millisecond), c.Profiles[1].Max)
+	assert.Equal(t, Duration(5*time.Minute), c.Profiles[2].Over)
+
+	require.NoError(t, os.WriteFile(filename, []byte(`{"profiles": [{"path": "/", "type": "gamma"}]}`), 0644))
+	_, err = LoadLatencyConfig(filename)
+	assert.EqualError(t, err, `latency profile 0 (/): unknown type "gamma"`)
+	require.NoError(t, os.WriteFile(filename, []byte(`{"profiles": [{"path": "/", "delay": "soon"}]}`), 0644))
+	_, err = LoadLatencyConfig(filename)
+	assert.Error(t, err)
+}
+
+func TestWithLatencyConfig(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", mock.Anything, mock.Anything, mock.Anything).Return(Response{})
+	s := NewServer(downstream, RecordLatencies(), WithLatencyConfig(LatencyConfig{Profiles: []LatencyProfile{
+		{Method: "GET", Path: "/slow/*", Type: "fixed", Delay: Duration(50 * time.Millisecond)},
+		{Path: "/**", Type: "fixed"},
+	}}))
+	defer s.Close()
+
+	for _, path := range []string{"/slow/1?x=y", "/fast"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	stats := s.LatencyStats()
+	assert.GreaterOrEqual(t, stats["GET /slow/1"].Max, 50*time.Millisecond)
+	assert.Less(t, stats["GET /fast"].Max, 50*time.Millisecond)
+
+	assert.Panics(t, func() { WithLatencyConfig(LatencyConfig{Profiles: []LatencyProfile{{Path: "/"}}}) })
+}
@@ -0,0 +1,111 @@
+package httpmock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// InMemory makes the server listen on an in-memory listener backed by net.Pipe instead of a TCP port, for
+// environments where binding ports is forbidden. Requests still go through the full HTTP stack, but only clients from
+// the server's Client method can reach it; the server's URL is just a placeholder.
+func InMemory() Option {
+	return func(s *Server) {
+		l := newPipeListener()
+		s.listener = l
+		s.dial = l.DialContext
+	}
+}
+
+// NewServerInPortRange constructs a new server listening on the first free port of the range [min, max] on the
+// loopback interface, and starts it. This is handy where only some ports are reachable, e.g. through local firewalls.
+// It panics if no port in the range is free.
+func NewServerInPortRange(min, max int, handler Handler, opts ...Option) *Server {
+	l, err := listenInPortRange(min, max)
+	if err != nil {
+		panic("httpmock: " + err.Error())
+	}
+	opts = append(opts, func(s *Server) {
+		s.listener = l
+	})
+	return NewServer(handler, opts...)
+}
+
+// listenInPortRange listens on the first free loopback port in [min, max].
+func listenInPortRange(min, max int) (net.Listener, error) {
+	for port := min; port <= max; port++ {
+		l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err == nil {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("no free port in range %d-%d", min, max)
+}
+
+// Client returns an HTTP client configured for making requests to the started server, like httptest.Server.Client:
+// it trusts the server's certificate when it was started with TLS. It must be used to reach servers listening somewhere
+// other than a TCP port, such as InMemory ones.
+func (s *Server) Client() *http.Client {
+	client := s.httpServer.Client()
+	if s.dial == nil {
+		return client
+	}
+	transport := client.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return s.dial(ctx)
+	}
+	return &http.Client{Transport: transport}
+}
+
+// pipeListener is a net.Listener whose connections are created in memory with net.Pipe.
+type pipeListener struct {
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+// DialContext connects to the listener, returning the client end of a new pipe.
+func (l *pipeListener) DialContext(ctx context.Context) (net.Conn, error) {
+	server, client := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "httpmock.pipe" }
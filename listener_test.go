@@ -0,0 +1,66 @@
+package httpmock
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemory(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/object/12345", mock.Anything).Return(Response{
+		Body: []byte(`{"status": "ok"}`),
+	}).Twice()
+
+	s := NewServer(downstream, InMemory())
+	defer s.Close()
+
+	client := s.Client()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(fmt.Sprintf("%s/object/12345", s.URL()))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, `{"status": "ok"}`, string(body))
+	}
+
+	downstream.AssertExpectations(t)
+}
+
+func TestNewServerInPortRange(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{})
+
+	// Occupy a port, then ask for a range starting at it
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+	port := taken.Addr().(*net.TCPAddr).Port
+
+	s := NewServerInPortRange(port, port+20, downstream)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL())
+	require.NoError(t, err)
+	actual, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	assert.Greater(t, actual, port)
+	assert.LessOrEqual(t, actual, port+20)
+
+	resp, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Panics(t, func() { NewServerInPortRange(port, port, downstream) })
+
+	downstream.AssertExpectations(t)
+}
@@ -0,0 +1,179 @@
+//go:build windows && go1.25
+
+package httpmock
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procCreateNamedPipeW    = kernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe    = kernel32.NewProc("ConnectNamedPipe")
+	procGetOverlappedResult = kernel32.NewProc("GetOverlappedResult")
+)
+
+const (
+	pipeAccessDuplex       = 0x3
+	pipeUnlimitedInstances = 255
+	pipeBufferSize         = 64 * 1024
+	errorPipeBusy          = syscall.Errno(231)
+	errorPipeConnected     = syscall.Errno(535)
+)
+
+// NamedPipe makes the server listen on the Windows named pipe with the given name, e.g. `\\.\pipe\docker_engine`,
+// instead of a TCP port, for testing code that talks to local agents over named pipes. The code under test dials the
+// pipe itself, or the server's Client method can be used; the server's URL is just a placeholder.
+//
+// It panics if the pipe can't be created. It requires Go 1.25 or later, whose os.NewFile supports overlapped I/O.
+func NamedPipe(name string) Option {
+	return func(s *Server) {
+		l, err := newNamedPipeListener(name)
+		if err != nil {
+			panic("httpmock: failed to listen on named pipe " + name + ": " + err.Error())
+		}
+		s.listener = l
+		s.dial = func(ctx context.Context) (net.Conn, error) {
+			return dialNamedPipe(ctx, name)
+		}
+	}
+}
+
+// namedPipeListener is a net.Listener accepting connections on a Windows named pipe. Each connection uses its own
+// instance of the pipe, the next of which is created as soon as one is connected.
+type namedPipeListener struct {
+	name string
+
+	mu     sync.Mutex
+	next   syscall.Handle
+	closed bool
+}
+
+func newNamedPipeListener(name string) (*namedPipeListener, error) {
+	h, err := createNamedPipe(name)
+	if err != nil {
+		return nil, err
+	}
+	return &namedPipeListener{name: name, next: h}, nil
+}
+
+func (l *namedPipeListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	h := l.next
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		syscall.CloseHandle(h)
+		return nil, net.ErrClosed
+	}
+
+	if err := connectNamedPipe(h); err != nil {
+		syscall.CloseHandle(h)
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		// Close connected to us to unblock the accept
+		syscall.CloseHandle(h)
+		return nil, net.ErrClosed
+	}
+	next, err := createNamedPipe(l.name)
+	if err != nil {
+		syscall.CloseHandle(h)
+		return nil, err
+	}
+	l.next = next
+	return pipeConn{os.NewFile(uintptr(h), l.name)}, nil
+}
+
+func (l *namedPipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	// ConnectNamedPipe can't be interrupted, so connect to the pending instance to wake up Accept
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if c, err := dialNamedPipe(ctx, l.name); err == nil {
+		c.Close()
+	}
+	return nil
+}
+
+func (l *namedPipeListener) Addr() net.Addr {
+	return pipeAddr{}
+}
+
+func createNamedPipe(name string) (syscall.Handle, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return syscall.InvalidHandle, err
+	}
+	r, _, err := procCreateNamedPipeW.Call(uintptr(unsafe.Pointer(p)), pipeAccessDuplex|syscall.FILE_FLAG_OVERLAPPED, 0,
+		pipeUnlimitedInstances, pipeBufferSize, pipeBufferSize, 0, 0)
+	if syscall.Handle(r) == syscall.InvalidHandle {
+		return syscall.InvalidHandle, err
+	}
+	return syscall.Handle(r), nil
+}
+
+// connectNamedPipe waits for a client to connect to the pipe instance h.
+func connectNamedPipe(h syscall.Handle) error {
+	var o syscall.Overlapped
+	r, _, err := procConnectNamedPipe.Call(uintptr(h), uintptr(unsafe.Pointer(&o)))
+	switch {
+	case r != 0, err == errorPipeConnected:
+		return nil
+	case err != syscall.ERROR_IO_PENDING:
+		return err
+	}
+	var n uint32
+	if r, _, err := procGetOverlappedResult.Call(uintptr(h), uintptr(unsafe.Pointer(&o)), uintptr(unsafe.Pointer(&n)), 1); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// dialNamedPipe connects to the named pipe, waiting for a free instance until ctx is done.
+func dialNamedPipe(ctx context.Context, name string) (net.Conn, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := syscall.CreateFile(p, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_EXISTING,
+			syscall.FILE_FLAG_OVERLAPPED, 0)
+		if err == nil {
+			return pipeConn{os.NewFile(uintptr(h), name)}, nil
+		}
+		if !errors.Is(err, errorPipeBusy) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// pipeConn adapts a connected pipe handle to a net.Conn. os.File supports deadlines on overlapped handles.
+type pipeConn struct {
+	*os.File
+}
+
+func (pipeConn) LocalAddr() net.Addr  { return pipeAddr{} }
+func (pipeConn) RemoteAddr() net.Addr { return pipeAddr{} }
@@ -0,0 +1,29 @@
+//go:build windows && go1.25
+
+package httpmock
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedPipe(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/_ping", mock.Anything).Return(Response{Body: []byte("OK")})
+
+	s := NewServer(downstream, NamedPipe(`\\.\pipe\httpmock-`+t.Name()))
+	defer s.Close()
+
+	resp, err := s.Client().Get(fmt.Sprintf("%s/_ping", s.URL()))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", string(body))
+
+	downstream.AssertExpectations(t)
+}
@@ -0,0 +1,68 @@
+package httpmock
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger receives the warnings and errors that httpmock logs rather than reporting to a test. Messages start with
+// "httpmock: " and may be followed by alternating keys and values, like those of log/slog, whose *slog.Logger
+// implements Logger. See WithLogger.
+type Logger interface {
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger makes the server, and its handler if it is one of the mock handlers of this package, log to l instead of
+// the standard logger, so that code embedding the mock can route its output. A nil Logger silences it.
+//
+//	s := httpmock.NewServer(downstream, httpmock.WithLogger(slog.Default().With("component", "downstream-mock")))
+func WithLogger(l Logger) Option {
+	return func(s *Server) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		s.logger = l
+		if h, ok := s.handler.(interface{ SetLogger(l Logger) }); ok {
+			h.SetLogger(l)
+		}
+	}
+}
+
+// stdLogger is the default Logger, logging to the standard logger with the values appended as key=value pairs.
+type stdLogger struct{}
+
+func (stdLogger) Warn(msg string, args ...interface{}) {
+	log.Print(formatLog(strings.Replace(msg, "httpmock: ", "httpmock: warning: ", 1), args))
+}
+
+func (stdLogger) Error(msg string, args ...interface{}) {
+	log.Print(formatLog(msg, args))
+}
+
+func formatLog(msg string, args []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+	return b.String()
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// log returns the server's Logger.
+func (s *Server) log() Logger {
+	if s.logger == nil {
+		return stdLogger{}
+	}
+	return s.logger
+}
@@ -0,0 +1,67 @@
+package httpmock
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingLogger is a Logger keeping the messages it receives along with their arguments.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+	args     [][]interface{}
+}
+
+func (l *recordingLogger) Warn(msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, "WARN "+msg)
+	l.args = append(l.args, args)
+}
+
+func (l *recordingLogger) Error(msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, "ERROR "+msg)
+	l.args = append(l.args, args)
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	downstream := &MockHandler{}
+	s := NewUnstartedServer(downstream, WithLogger(logger))
+	downstream.On("Handle", "POST", "/upload", mock.Anything).Return(Response{})
+	downstream.On("Handle", "POST", "/upload", mock.Anything).Return(Response{})
+
+	s.reportError("failed to %s", "frobnicate")
+
+	err := errors.New("connection reset")
+	r := httptest.NewRequest("POST", "/upload", iotest.ErrReader(err))
+	s.HTTPHandler().ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, []string{
+		"WARN httpmock: expectation Handle(POST, /upload, mock.Anything) is shadowed by an identical one registered " +
+			"before it, so it will never be used",
+		"ERROR httpmock: failed to frobnicate",
+		"WARN httpmock: failed to read request body",
+	}, logger.messages)
+	assert.Equal(t, []interface{}{"method", "POST", "path", "/upload", "error", err}, logger.args[2])
+}
+
+func TestWithNilLogger(t *testing.T) {
+	s := NewUnstartedServer(&OKHandler{}, WithLogger(nil))
+	assert.NotPanics(t, func() { s.reportError("failed") })
+}
+
+func TestFormatLog(t *testing.T) {
+	assert.Equal(t, "httpmock: failed", formatLog("httpmock: failed", nil))
+	assert.Equal(t, "httpmock: failed method=GET status=500 dangling",
+		formatLog("httpmock: failed", []interface{}{"method", http.MethodGet, "status", 500, "dangling"}))
+}
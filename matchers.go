@@ -0,0 +1,172 @@
+package httpmock
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AnyMethod can be used in place of the method argument to match requests of any HTTP method. It is equivalent to
+// mock.Anything, but reads better in expectations.
+const AnyMethod = mock.Anything
+
+// MethodIn returns a mock.MatchedBy func to check if the method argument is one of the given HTTP methods.
+//
+//	downstream.On("Handle", httpmock.MethodIn("GET", "HEAD"), "/object/12345", mock.Anything)
+func MethodIn(methods ...string) interface{} {
+	return mock.MatchedBy(func(method string) bool {
+		for _, m := range methods {
+			if method == m {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// SafeMethods returns a mock.MatchedBy func to check if the method argument is one of the "safe" HTTP methods as
+// defined by RFC 9110: GET, HEAD, OPTIONS and TRACE.
+func SafeMethods() interface{} {
+	return MethodIn(http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace)
+}
+
+// AnyBody can be used in place of the body argument to match any request body. It is equivalent to mock.Anything,
+// but reads better in expectations.
+func AnyBody() interface{} {
+	return mock.Anything
+}
+
+// EmptyBody returns a mock.MatchedBy func to check if the body argument is empty. A nil body and a zero-length body are
+// treated the same, unlike a literal []byte{} or []byte(nil) in an expectation.
+func EmptyBody() interface{} {
+	return mock.MatchedBy(func(body []byte) bool {
+		return len(body) == 0
+	})
+}
+
+// ContentTypeMatcher returns a mock.MatchedBy func to check if the headers argument has a Content-Type of the given
+// media type. Parameters such as charset are ignored, and the comparison is case-insensitive, so
+// ContentTypeMatcher("application/json") matches "Application/JSON; charset=utf-8".
+func ContentTypeMatcher(mediaType string) interface{} {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	return mock.MatchedBy(func(headers http.Header) bool {
+		actual, _, err := mime.ParseMediaType(headers.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+		return actual == mediaType
+	})
+}
+
+// BodySHA256Matcher returns a mock.MatchedBy func to check if the SHA-256 digest of the body argument equals the given
+// hex-encoded digest. This avoids keeping large expected bodies in memory in upload tests.
+func BodySHA256Matcher(hexDigest string) interface{} {
+	hexDigest = strings.ToLower(hexDigest)
+	return mock.MatchedBy(func(body []byte) bool {
+		sum := sha256.Sum256(body)
+		return hex.EncodeToString(sum[:]) == hexDigest
+	})
+}
+
+// ContentMD5Matcher returns a mock.MatchedBy func to check if the RequestInfo argument of HandleRequest carries a
+// Content-MD5 header (RFC 1864) that matches its body. Requests without the header don't match.
+//
+//	downstream.On("HandleRequest", httpmock.ContentMD5Matcher())
+func ContentMD5Matcher() interface{} {
+	return RequestMatcher(func(req RequestInfo) bool {
+		expected := req.Header.Get("Content-MD5")
+		if expected == "" {
+			return false
+		}
+		sum := md5.Sum(req.Body)
+		return base64.StdEncoding.EncodeToString(sum[:]) == expected
+	})
+}
+
+// HeaderMatcherExactCase returns a mock.MatchedBy func to check if the RequestInfo argument of HandleRequest has a
+// header with the given value whose name was sent in exactly the given case, e.g. "x-api-key" rather than
+// "X-Api-Key". Requests whose raw header names aren't available (see RequestInfo.RawHeaderNames) don't match.
+//
+//	downstream.On("HandleRequest", httpmock.HeaderMatcherExactCase("x-api-key", "secret"))
+func HeaderMatcherExactCase(key, value string) interface{} {
+	return RequestMatcher(func(req RequestInfo) bool {
+		for _, name := range req.RawHeaderNames {
+			if name == key {
+				return req.Header.Get(key) == value
+			}
+		}
+		return false
+	})
+}
+
+// PathGlobMatcher returns a mock.MatchedBy func to check if the path argument matches a glob pattern. Patterns are
+// matched segment by segment: "*" matches exactly one path segment (and supports the usual path.Match syntax within
+// a segment, e.g. "*.json"), while "**" matches any number of segments, including none. The query string, if any, is
+// ignored.
+//
+//	downstream.On("Handle", "GET", httpmock.PathGlobMatcher("/files/*/chunks/**"), mock.Anything)
+func PathGlobMatcher(pattern string) interface{} {
+	patternSegments := splitPath(pattern)
+	return mock.MatchedBy(func(requestURI string) bool {
+		return globMatch(patternSegments, splitPath(stripQuery(requestURI)))
+	})
+}
+
+// PathPrefixMatcher returns a mock.MatchedBy func to check if the path argument starts with the given prefix. The query
+// string, if any, is ignored. This makes it easy to cover a whole API subtree with a single expectation.
+//
+//	downstream.On("Handle", mock.Anything, httpmock.PathPrefixMatcher("/api/v1/"), mock.Anything)
+func PathPrefixMatcher(prefix string) interface{} {
+	return mock.MatchedBy(func(requestURI string) bool {
+		return strings.HasPrefix(stripQuery(requestURI), prefix)
+	})
+}
+
+// globMatch does the actual work for PathGlobMatcher, backtracking over "**" segments.
+func globMatch(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if globMatch(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], segments[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// splitPath splits a URL path into its segments, ignoring the leading slash.
+func splitPath(p string) []string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// stripQuery removes the query string from a request URI, leaving only the path.
+func stripQuery(requestURI string) string {
+	if i := strings.IndexByte(requestURI, '?'); i >= 0 {
+		return requestURI[:i]
+	}
+	return requestURI
+}
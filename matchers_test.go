@@ -0,0 +1,174 @@
+package httpmock
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// matches reports whether the given expected argument (a value or a matcher) matches the actual argument.
+func matches(expected, actual interface{}) bool {
+	_, diffs := mock.Arguments{expected}.Diff([]interface{}{actual})
+	return diffs == 0
+}
+
+func TestPathGlobMatcher(t *testing.T) {
+	m := PathGlobMatcher("/files/*/chunks/**")
+	assert.True(t, matches(m, "/files/abc/chunks"))
+	assert.True(t, matches(m, "/files/abc/chunks/1"))
+	assert.True(t, matches(m, "/files/abc/chunks/1/2?part=3"))
+	assert.False(t, matches(m, "/files/abc/def/chunks/1"))
+	assert.False(t, matches(m, "/files/chunks/1"))
+
+	m = PathGlobMatcher("/objects/*.json")
+	assert.True(t, matches(m, "/objects/12345.json"))
+	assert.False(t, matches(m, "/objects/12345.xml"))
+	assert.False(t, matches(m, "/objects/a/12345.json"))
+}
+
+func TestPathPrefixMatcher(t *testing.T) {
+	m := PathPrefixMatcher("/api/v1/")
+	assert.True(t, matches(m, "/api/v1/objects"))
+	assert.True(t, matches(m, "/api/v1/?q=1"))
+	assert.False(t, matches(m, "/api/v2/objects"))
+	assert.False(t, matches(m, "/other?next=/api/v1/"))
+}
+
+func TestMethodMatchers(t *testing.T) {
+	assert.True(t, matches(AnyMethod, "PATCH"))
+
+	m := MethodIn("GET", "HEAD")
+	assert.True(t, matches(m, "GET"))
+	assert.True(t, matches(m, "HEAD"))
+	assert.False(t, matches(m, "POST"))
+
+	assert.True(t, matches(SafeMethods(), "OPTIONS"))
+	assert.False(t, matches(SafeMethods(), "DELETE"))
+}
+
+func TestBodyMatchers(t *testing.T) {
+	assert.True(t, matches(AnyBody(), []byte("anything")))
+
+	assert.True(t, matches(EmptyBody(), []byte(nil)))
+	assert.True(t, matches(EmptyBody(), []byte{}))
+	assert.False(t, matches(EmptyBody(), []byte("x")))
+}
+
+func TestContentTypeMatcher(t *testing.T) {
+	m := ContentTypeMatcher("application/json")
+	assert.True(t, matches(m, http.Header{"Content-Type": {"application/json"}}))
+	assert.True(t, matches(m, http.Header{"Content-Type": {"Application/JSON; charset=utf-8"}}))
+	assert.False(t, matches(m, http.Header{"Content-Type": {"text/plain"}}))
+	assert.False(t, matches(m, http.Header{}))
+}
+
+func TestChecksumMatchers(t *testing.T) {
+	// sha256("hello")
+	m := BodySHA256Matcher("2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824")
+	assert.True(t, matches(m, []byte("hello")))
+	assert.False(t, matches(m, []byte("goodbye")))
+
+	m = ContentMD5Matcher()
+	req := RequestInfo{Header: http.Header{"Content-Md5": {"XUFAKrxLKna5cZ2REBfFkg=="}}, Body: []byte("hello")}
+	assert.True(t, matches(m, req))
+	req.Body = []byte("goodbye")
+	assert.False(t, matches(m, req))
+	assert.False(t, matches(m, RequestInfo{Body: []byte("hello")}))
+}
+
+func TestETagMatchers(t *testing.T) {
+	etag := ETag([]byte("hello"))
+	assert.Equal(t, `"2cf24dba5fb0a30e"`, etag)
+	assert.Equal(t, `W/"2cf24dba5fb0a30e"`, WeakETag([]byte("hello")))
+	assert.Equal(t, etag, WithETag(Response{Body: []byte("hello")}).Header.Get("ETag"))
+	tagged := WithETag(Response{BodyObject: map[string]int{"a": 1}})
+	assert.Equal(t, ETag([]byte(`{"a":1}`)), tagged.Header.Get("ETag"))
+	assert.Equal(t, `{"a":1}`, string(tagged.Body))
+	computed := WithETag(Response{compute: func(RequestInfo) Response { return Response{Body: []byte("hello")} }})
+	assert.Equal(t, etag, computed.compute(RequestInfo{}).Header.Get("ETag"))
+
+	ifMatch := IfMatchMatcher(etag)
+	assert.True(t, matches(ifMatch, http.Header{"If-Match": {`"other", ` + etag}}))
+	assert.True(t, matches(ifMatch, http.Header{"If-Match": {"*"}}))
+	assert.False(t, matches(ifMatch, http.Header{"If-Match": {"W/" + etag}}))
+	assert.False(t, matches(ifMatch, http.Header{}))
+
+	ifNoneMatch := IfNoneMatchMatcher(etag)
+	assert.True(t, matches(ifNoneMatch, http.Header{"If-None-Match": {`W/"a,b", W/` + etag}}))
+	assert.False(t, matches(ifNoneMatch, http.Header{"If-None-Match": {`"a,b"`}}))
+	assert.False(t, matches(ifNoneMatch, http.Header{}))
+}
+
+func TestQueryMatchers(t *testing.T) {
+	req := RequestInfo{Path: "/items?id=1&id=2&sort=asc"}
+	assert.Equal(t, url.Values{"id": {"1", "2"}, "sort": {"asc"}}, req.Query())
+	assert.Equal(t, url.Values{}, ParseQuery("/items"))
+
+	assert.True(t, matches(QueryMatcher("/items", url.Values{"sort": {"asc"}, "id": {"1", "2"}}), req.Path))
+	assert.False(t, matches(QueryMatcher("/items", url.Values{"sort": {"asc"}, "id": {"2", "1"}}), req.Path))
+	assert.False(t, matches(QueryMatcher("/items", url.Values{"id": {"1", "2"}}), req.Path))
+	assert.False(t, matches(QueryMatcher("/other", url.Values{"sort": {"asc"}, "id": {"1", "2"}}), req.Path))
+	assert.True(t, matches(QueryMatcher("/items", nil), "/items"))
+
+	assert.True(t, matches(QueryParamMatcher("id", "1", "2"), req.Path))
+	assert.False(t, matches(QueryParamMatcher("id", "1"), req.Path))
+	assert.True(t, matches(QueryParamMatcher("sort"), req.Path))
+	assert.False(t, matches(QueryParamMatcher("page"), req.Path))
+}
+
+func TestMatrixParams(t *testing.T) {
+	path, params := MatrixParams("/cars;color=red;year=2020/models;make=x%20y?page=2")
+	assert.Equal(t, "/cars/models", path)
+	assert.Equal(t, []url.Values{{"color": {"red"}, "year": {"2020"}}, {"make": {"x y"}}}, params)
+
+	assert.True(t, matches(MatrixPathMatcher("/cars/models"), "/cars;color=red/models"))
+	assert.True(t, matches(MatrixParamMatcher("color", "red"), "/cars;color=red/models"))
+	assert.True(t, matches(MatrixParamMatcher("year"), "/cars;color=red;year=2020/models"))
+	assert.False(t, matches(MatrixParamMatcher("year"), "/cars;color=red/models"))
+}
+
+func TestAllowQuerySemicolons(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", QueryMatcher("/legacy", url.Values{"a": {"1"}, "b": {"2"}}), mock.Anything).Return(
+		Response{Status: 204})
+	s := NewServer(downstream, AllowQuerySemicolons())
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/legacy?a=1;b=2")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 204, resp.StatusCode)
+}
+
+func TestHostMatcher(t *testing.T) {
+	assert.Equal(t, "mnchen-3ya", punycode("münchen"))
+	assert.Equal(t, "xn--bcher-kva.example", normalizeHost("Bücher.Example."))
+	assert.Equal(t, "xn--wgv71a119e.jp", normalizeHost("日本語.jp"))
+
+	assert.True(t, matches(HostMatcher("bücher.example"), RequestInfo{Host: "xn--bcher-kva.example:8080"}))
+	assert.True(t, matches(HostMatcher("xn--bcher-kva.example:8080"), RequestInfo{Host: "BÜCHER.example:8080"}))
+	assert.False(t, matches(HostMatcher("bücher.example:443"), RequestInfo{Host: "xn--bcher-kva.example:8080"}))
+	assert.False(t, matches(HostMatcher("bucher.example"), RequestInfo{Host: "xn--bcher-kva.example"}))
+	assert.True(t, matches(HostMatcher("::1"), RequestInfo{Host: "[::1]:80"}))
+}
+
+func TestHostMatcherServer(t *testing.T) {
+	downstream := &MockHandlerWithRequest{}
+	downstream.On("HandleRequest", HostMatcher("bücher.example")).Return(Response{Status: 204})
+	s := NewServer(downstream)
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", s.URL()+"/", nil)
+	require.NoError(t, err)
+	// The client sends the punycode form
+	req.Host = "bücher.example"
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, "xn--bcher-kva.example", downstream.Calls[0].Arguments.Get(0).(RequestInfo).Host)
+}
@@ -0,0 +1,75 @@
+package httpmock
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AllowQuerySemicolons makes the server accept semicolons as query parameter separators, as legacy APIs did, by
+// converting them to ampersands in the path passed to the Handler, like http.AllowQuerySemicolons. Query, ParseQuery
+// and the query matchers then see "?a=1;b=2" as two parameters, while by default Go ignores pairs with semicolons.
+func AllowQuerySemicolons() Option {
+	return WithMiddleware(func(req RequestInfo, next func(RequestInfo) Response) Response {
+		if path, query, ok := strings.Cut(req.Path, "?"); ok && strings.Contains(query, ";") {
+			req.Path = path + "?" + strings.ReplaceAll(query, ";", "&")
+		}
+		return next(req)
+	})
+}
+
+// MatrixParams parses the matrix-style parameters of the path of a request URI, such as the path argument of Handle,
+// e.g. "/cars;color=red;year=2020/models;make=x". It returns the path without them ("/cars/models") and the
+// parameters of each of its segments, in order. The query string, if any, is ignored.
+func MatrixParams(requestURI string) (string, []url.Values) {
+	segments := strings.Split(stripQuery(requestURI), "/")
+	params := make([]url.Values, 0, len(segments))
+	for i, segment := range segments {
+		name, rawParams, _ := strings.Cut(segment, ";")
+		segments[i] = name
+		values := url.Values{}
+		for _, pair := range strings.Split(rawParams, ";") {
+			if pair == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(pair, "=")
+			key, err1 := url.PathUnescape(key)
+			value, err2 := url.PathUnescape(value)
+			if err1 == nil && err2 == nil {
+				values.Add(key, value)
+			}
+		}
+		if i > 0 || name != "" {
+			params = append(params, values)
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// MatrixPathMatcher returns a mock.MatchedBy func to check if the path argument is path once its matrix parameters
+// are removed, e.g. MatrixPathMatcher("/cars/models") matches "/cars;color=red/models".
+func MatrixPathMatcher(path string) interface{} {
+	return mock.MatchedBy(func(requestURI string) bool {
+		p, _ := MatrixParams(requestURI)
+		return p == path
+	})
+}
+
+// MatrixParamMatcher returns a mock.MatchedBy func to check if the path argument has the matrix parameter key, in any
+// of its segments, with exactly the given values, in order. Without values, it only checks that the parameter is
+// present.
+func MatrixParamMatcher(key string, values ...string) interface{} {
+	return mock.MatchedBy(func(requestURI string) bool {
+		_, params := MatrixParams(requestURI)
+		var actual []string
+		for _, p := range params {
+			actual = append(actual, p[key]...)
+		}
+		if actual == nil {
+			return false
+		}
+		return len(values) == 0 || reflect.DeepEqual(actual, values)
+	})
+}
@@ -0,0 +1,178 @@
+package httpmock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsBuckets are the upper bounds in seconds of the latency histogram buckets, Prometheus' default ones.
+var metricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// ExposeMetrics makes the server answer GET requests for path, typically "/metrics", with metrics in the Prometheus
+// text format, without calling the Handler or any middleware, nor recording them in the history. It is meant for
+// mocks running as long-lived servers in integration environments, which can then be monitored like the services they
+// stand in for. The metrics are:
+//
+//   - httpmock_requests_total, the requests answered by the handler by method, path and status code (0 for responses
+//     hijacking the connection)
+//   - httpmock_request_duration_seconds, a histogram of how long they took to answer
+//   - httpmock_expectation_matches_total, the requests matched by each expectation, for the mock handlers, by the
+//     expectation's index in the order of registration and its description
+//   - httpmock_unmatched_requests_total, the requests that matched no expectation, for the mock handlers
+//
+// Paths don't include the query string. The metrics path is served even if it is ignored with IgnorePaths, e.g. with
+// TelemetryPaths.
+func ExposeMetrics(path string) Option {
+	return func(s *Server) {
+		s.metrics = &metrics{path: path}
+	}
+}
+
+// metrics holds the metrics of a server with the ExposeMetrics option.
+type metrics struct {
+	path string
+
+	mu     sync.Mutex
+	routes map[metricsRoute]*metricsSeries
+}
+
+type metricsRoute struct {
+	method, path string
+	status       int
+}
+
+type metricsSeries struct {
+	count   int
+	sum     time.Duration
+	buckets []int // Non-cumulative counts, the last one for +Inf
+}
+
+func (m *metrics) record(method, path string, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.routes == nil {
+		m.routes = make(map[metricsRoute]*metricsSeries)
+	}
+	route := metricsRoute{method: method, path: path, status: status}
+	series := m.routes[route]
+	if series == nil {
+		series = &metricsSeries{buckets: make([]int, len(metricsBuckets)+1)}
+		m.routes[route] = series
+	}
+	series.count++
+	series.sum += d
+	series.buckets[sort.SearchFloat64s(metricsBuckets, d.Seconds())]++
+}
+
+// reset forgets all recorded requests.
+func (m *metrics) reset() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = nil
+}
+
+// serveMetrics answers r with the server's metrics if it is for the metrics path, reporting whether it was.
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) bool {
+	if s.metrics == nil || r.Method != http.MethodGet || r.URL.Path != s.metrics.path {
+		return false
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.writeMetrics(w)
+	return true
+}
+
+func (s *Server) writeMetrics(w io.Writer) {
+	s.metrics.mu.Lock()
+	routes := make([]metricsRoute, 0, len(s.metrics.routes))
+	series := make(map[metricsRoute]metricsSeries, len(s.metrics.routes))
+	for route, sr := range s.metrics.routes {
+		routes = append(routes, route)
+		series[route] = metricsSeries{count: sr.count, sum: sr.sum, buckets: append([]int(nil), sr.buckets...)}
+	}
+	s.metrics.mu.Unlock()
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+
+	fmt.Fprint(w, "# HELP httpmock_requests_total Requests answered by the handler.\n"+
+		"# TYPE httpmock_requests_total counter\n")
+	for _, route := range routes {
+		fmt.Fprintf(w, "httpmock_requests_total{%s} %d\n", route.labels(), series[route].count)
+	}
+
+	fmt.Fprint(w, "# HELP httpmock_request_duration_seconds How long the handler took to answer requests.\n"+
+		"# TYPE httpmock_request_duration_seconds histogram\n")
+	for _, route := range routes {
+		sr := series[route]
+		cumulative := 0
+		for i, count := range sr.buckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(metricsBuckets) {
+				le = strconv.FormatFloat(metricsBuckets[i], 'g', -1, 64)
+			}
+			fmt.Fprintf(w, "httpmock_request_duration_seconds_bucket{%s,le=%q} %d\n", route.labels(), le, cumulative)
+		}
+		fmt.Fprintf(w, "httpmock_request_duration_seconds_sum{%s} %s\n", route.labels(),
+			strconv.FormatFloat(sr.sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "httpmock_request_duration_seconds_count{%s} %d\n", route.labels(), sr.count)
+	}
+
+	h, ok := s.handler.(expectationCounter)
+	if !ok {
+		return
+	}
+	expectations, unmatched := h.metricsCounts()
+	fmt.Fprint(w, "# HELP httpmock_expectation_matches_total Requests matched by each expectation.\n"+
+		"# TYPE httpmock_expectation_matches_total counter\n")
+	for i, e := range expectations {
+		// The index tells apart identical expectations, which are allowed when limited with Once or Times
+		fmt.Fprintf(w, "httpmock_expectation_matches_total{index=\"%d\",expectation=\"%s(%s)\"} %d\n",
+			i, escapeLabel(e.Method), escapeLabel(formatArgs(e.Arguments)), e.Matched)
+	}
+	fmt.Fprintf(w, "# HELP httpmock_unmatched_requests_total Requests that matched no expectation.\n"+
+		"# TYPE httpmock_unmatched_requests_total counter\n"+
+		"httpmock_unmatched_requests_total %d\n", unmatched)
+}
+
+// expectationCounter is implemented by the mock handlers.
+type expectationCounter interface {
+	metricsCounts() (expectations []ExpectationUsage, unmatched int)
+}
+
+// responseStatus returns the status code resp is sent with, or 0 if it hijacks the connection.
+func responseStatus(resp Response) int {
+	switch {
+	case resp.Hijack != nil:
+		return 0
+	case resp.Status == 0:
+		return http.StatusOK
+	default:
+		return resp.Status
+	}
+}
+
+func (r metricsRoute) labels() string {
+	return fmt.Sprintf(`method="%s",path="%s",code="%d"`, escapeLabel(r.method), escapeLabel(r.path), r.status)
+}
+
+// escapeLabel escapes a label value as per the Prometheus text format.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
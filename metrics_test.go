@@ -0,0 +1,68 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposeMetrics(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/object/1?x=1", []byte{}).Return(Response{Body: []byte("ok")})
+	downstream.On("Handle", "POST", "/never", []byte{}).Return(Response{}).Once()
+	downstream.On("Handle", "POST", "/never", []byte{}).Return(Response{}).Once()
+	s := NewServer(downstream, ExposeMetrics("/metrics"), RecordHistory())
+	defer s.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(s.URL() + "/object/1?x=1")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(s.URL() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	metrics := string(body)
+	assert.Contains(t, metrics, "# TYPE httpmock_requests_total counter\n"+
+		`httpmock_requests_total{method="GET",path="/object/1",code="200"} 2`+"\n")
+	assert.Contains(t, metrics, `httpmock_request_duration_seconds_bucket{method="GET",path="/object/1",code="200",le="+Inf"} 2`)
+	assert.Contains(t, metrics, `httpmock_request_duration_seconds_count{method="GET",path="/object/1",code="200"} 2`)
+	assert.Contains(t, metrics,
+		`httpmock_expectation_matches_total{index="0",expectation="Handle(GET, /object/1?x=1, \"\")"} 2`)
+	// Identical expectations limited with Once are told apart by their index
+	assert.Contains(t, metrics, `httpmock_expectation_matches_total{index="1",expectation="Handle(POST, /never, \"\")"} 0`)
+	assert.Contains(t, metrics, `httpmock_expectation_matches_total{index="2",expectation="Handle(POST, /never, \"\")"} 0`)
+	assert.Contains(t, metrics, "httpmock_unmatched_requests_total 0\n")
+	assert.Len(t, s.History(), 2, "metrics requests should not be recorded")
+
+	s.Reset()
+	resp, err = http.Get(s.URL() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "httpmock_requests_total{")
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
+
+func TestExposeMetricsWithIgnoredTelemetry(t *testing.T) {
+	s := NewServer(&OKHandler{}, IgnorePaths(TelemetryPaths...), ExposeMetrics("/metrics"))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "# TYPE httpmock_requests_total counter")
+}
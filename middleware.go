@@ -0,0 +1,165 @@
+package httpmock
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Middleware wraps the handling of requests by a Server. It receives the request and a next func calling the rest of
+// the chain (ending with the Handler), so it can modify the request before calling next, modify the response that next
+// returns, or return a response of its own without calling next at all. Add middleware with the WithMiddleware option.
+type Middleware func(req RequestInfo, next func(RequestInfo) Response) Response
+
+// middlewareState tells stateful middleware when to start over: it holds the number of Server.Reset calls seen so far,
+// and expired reports whether the server was Reset since, in which case the caller clears its state. Callers hold
+// their own lock. Requests that don't come from a Server, like those of ServeRequest, never expire it.
+type middlewareState struct {
+	resets int64
+}
+
+func (m *middlewareState) expired(req RequestInfo) bool {
+	if req.resets <= m.resets {
+		return false
+	}
+	m.resets = req.resets
+	return true
+}
+
+// SecurityHeaders returns a Middleware adding standard security headers to every response, unless the response
+// already sets them: Strict-Transport-Security, X-Content-Type-Options, X-Frame-Options, Referrer-Policy and
+// Content-Security-Policy. This is useful when testing clients or scanners that check a downstream's header hygiene.
+func SecurityHeaders() Middleware {
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		resp := next(req)
+		resp.Header = cloneHeader(resp.Header)
+		for key, val := range securityHeaders {
+			if resp.Header.Get(key) == "" {
+				resp.Header.Set(key, val)
+			}
+		}
+		return resp
+	}
+}
+
+// DefaultHeaders returns a Middleware adding the given headers to every response that doesn't set them itself, e.g. a
+// Content-Type or a Server header, saving stubs from repeating them. A response overrides a default by setting the same
+// header, with all of its values replacing the default ones.
+func DefaultHeaders(header http.Header) Middleware {
+	header = header.Clone()
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		resp := next(req)
+		resp.Header = cloneHeader(resp.Header)
+		for key, vals := range header {
+			if len(resp.Header.Values(key)) == 0 {
+				for _, val := range vals {
+					resp.Header.Add(key, val)
+				}
+			}
+		}
+		return resp
+	}
+}
+
+// OnPaths returns a Middleware applying mw only to requests whose path (ignoring the query string) matches the glob
+// pattern, see PathGlobMatcher, and passing other requests straight on.
+//
+//	httpmock.WithMiddleware(httpmock.OnPaths("/v1/**", httpmock.RewriteURLsToMock("https://api.example.com")))
+func OnPaths(pattern string, mw Middleware) Middleware {
+	patternSegments := splitPath(pattern)
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		if globMatch(patternSegments, splitPath(stripQuery(req.Path))) {
+			return mw(req, next)
+		}
+		return next(req)
+	}
+}
+
+// RewriteResponse returns a Middleware passing every response through fn once the handler has returned it, e.g. to
+// inject headers or patch bodies. fn must not modify the response's Header or Body in place, since they may be shared
+// with other responses (e.g. a Response returned by a mock several times), but replace them.
+func RewriteResponse(fn func(req RequestInfo, resp Response) Response) Middleware {
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		return fn(req, next(req))
+	}
+}
+
+// RewriteRequest returns a Middleware passing every request through fn before it goes on to be matched, to normalize
+// what the expectations see. fn must not modify the request's Header or Body in place, but replace them.
+func RewriteRequest(fn func(req RequestInfo) RequestInfo) Middleware {
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		return next(fn(req))
+	}
+}
+
+// StripPathPrefix returns a Middleware removing the start of request paths matching the regular expression pattern,
+// such as a volatile API version segment, so that expectations don't need to include it. It panics if pattern
+// doesn't compile.
+//
+//	httpmock.WithMiddleware(httpmock.StripPathPrefix(`/v[0-9.]+`)) // "/v2.1/orders" is matched as "/orders"
+func StripPathPrefix(pattern string) Middleware {
+	re := regexp.MustCompile(`^(?:` + pattern + `)`)
+	return RewriteRequest(func(req RequestInfo) RequestInfo {
+		if loc := re.FindStringIndex(req.Path); loc != nil {
+			req.Path = req.Path[loc[1]:]
+			if !strings.HasPrefix(req.Path, "/") {
+				req.Path = "/" + req.Path
+			}
+		}
+		return req
+	})
+}
+
+// DropHeaders returns a Middleware removing the given headers from requests before they are matched, such as noisy
+// tracing or user agent headers.
+func DropHeaders(keys ...string) Middleware {
+	return RewriteRequest(func(req RequestInfo) RequestInfo {
+		req.Header = cloneHeader(req.Header)
+		for _, key := range keys {
+			req.Header.Del(key)
+		}
+		return req
+	})
+}
+
+// RewriteURLsToMock returns a Middleware replacing the upstream base URL (e.g. "https://api.example.com") in response
+// bodies and in the Location, Content-Location and Link headers with the URL the client used to reach the mock, so
+// that clients replaying recorded fixtures follow links back to the mock. Responses with a BodyObject are marshaled
+// first.
+func RewriteURLsToMock(upstream string) Middleware {
+	upstream = strings.TrimSuffix(upstream, "/")
+	return RewriteResponse(func(req RequestInfo, resp Response) Response {
+		scheme := "http"
+		if req.TLS != nil {
+			scheme = "https"
+		}
+		mock := scheme + "://" + req.Host
+		resp = renderBodyObject(resp)
+		resp.Body = bytes.ReplaceAll(resp.Body, []byte(upstream), []byte(mock))
+		resp.Header = cloneHeader(resp.Header)
+		for _, key := range []string{"Location", "Content-Location", "Link"} {
+			for i, val := range resp.Header[key] {
+				resp.Header[key][i] = strings.ReplaceAll(val, upstream, mock)
+			}
+		}
+		return resp
+	})
+}
+
+var securityHeaders = map[string]string{
+	"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+	"X-Content-Type-Options":    "nosniff",
+	"X-Frame-Options":           "DENY",
+	"Referrer-Policy":           "no-referrer",
+	"Content-Security-Policy":   "default-src 'none'",
+}
+
+// cloneHeader returns a copy of h that can be modified without affecting h, which may be shared with other responses
+// (e.g. a Response returned by a mock several times). Unlike h.Clone, it never returns nil.
+func cloneHeader(h http.Header) http.Header {
+	if h == nil {
+		return make(http.Header)
+	}
+	return h.Clone()
+}
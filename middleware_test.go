@@ -0,0 +1,108 @@
+package httpmock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/rewritten", mock.Anything).Return(Response{
+		Header: http.Header{"X-Frame-Options": {"SAMEORIGIN"}},
+	})
+
+	rewrite := func(req RequestInfo, next func(RequestInfo) Response) Response {
+		req.Path = "/rewritten"
+		return next(req)
+	}
+	s := NewServer(downstream, WithMiddleware(SecurityHeaders(), rewrite))
+	defer s.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%s/object/12345", s.URL()))
+	require.NoError(t, err)
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+	assert.Equal(t, "SAMEORIGIN", resp.Header.Get("X-Frame-Options"))
+	assert.NotEmpty(t, resp.Header.Get("Strict-Transport-Security"))
+
+	downstream.AssertExpectations(t)
+}
+
+func TestWithDefaultHeaders(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/default", mock.Anything).Return(Response{Body: []byte(`{}`)})
+	downstream.On("Handle", "GET", "/override", mock.Anything).Return(Response{
+		Header: http.Header{"Content-Type": {"text/plain"}},
+	})
+
+	s := NewServer(downstream, WithDefaultHeaders(http.Header{
+		"Content-Type": {"application/json"},
+		"Server":       {"upstream/1.0"},
+	}))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/default")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "upstream/1.0", resp.Header.Get("Server"))
+
+	resp, err = http.Get(s.URL() + "/override")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "upstream/1.0", resp.Header.Get("Server"))
+}
+
+func TestRewriteURLsToMock(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/v1/orders", mock.Anything).Return(Response{
+		Header: http.Header{"Link": {`<https://api.example.com/v1/orders?page=2>; rel="next"`}},
+		Body:   []byte(`{"self": "https://api.example.com/v1/orders"}`),
+	})
+	downstream.On("Handle", "GET", "/v2/orders", mock.Anything).Return(Response{
+		Body: []byte(`{"self": "https://api.example.com/v2/orders"}`),
+	})
+
+	s := NewServer(downstream, WithMiddleware(OnPaths("/v1/**", RewriteURLsToMock("https://api.example.com/"))))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/v1/orders")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fmt.Sprintf(`{"self": "%s/v1/orders"}`, s.URL()), string(body))
+	assert.Equal(t, fmt.Sprintf(`<%s/v1/orders?page=2>; rel="next"`, s.URL()), resp.Header.Get("Link"))
+
+	resp, err = http.Get(s.URL() + "/v2/orders")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, `{"self": "https://api.example.com/v2/orders"}`, string(body))
+}
+
+func TestRequestRewriting(t *testing.T) {
+	downstream := NewMockHandlerWithHeaders(t)
+	downstream.On("HandleWithHeaders", "GET", "/orders?id=1", http.Header{"Accept-Encoding": {"gzip"}},
+		mock.Anything).Return(Response{Status: 204})
+
+	s := NewServer(downstream, WithMiddleware(StripPathPrefix(`/v[0-9.]+`), DropHeaders("User-Agent", "Traceparent")))
+	defer s.Close()
+
+	for _, path := range []string{"/v2.1/orders?id=1", "/v3/orders?id=1"} {
+		req, err := http.NewRequest("GET", s.URL()+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, 204, resp.StatusCode)
+	}
+}
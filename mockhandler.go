@@ -2,6 +2,7 @@ package httpmock
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -9,34 +10,174 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-// MockHandler is a httpmock.Handler that uses github.com/stretchr/testify/mock.
-type MockHandler struct {
+// mockHandler is the testify mock shared by the mock handlers, whose expectations are registered and tracked through
+// a tracker. It provides the methods they have in common.
+type mockHandler struct {
 	mock.Mock
+	tracker tracker
 }
 
-// Handle makes this implement the Handler interface.
-func (m *MockHandler) Handle(method, path string, body []byte) Response {
-	args := m.Called(method, path, body)
-	return args.Get(0).(Response)
+// On registers an expectation like mock.Mock.On, warning if it is shadowed by an identical earlier expectation that
+// isn't limited with Once or Times, since it would never be used. See StrictRegistration.
+func (m *mockHandler) On(methodName string, arguments ...interface{}) *mock.Call {
+	return m.tracker.on(&m.Mock, methodName, arguments)
 }
 
-// MockHandlerWithHeaders is a httpmock.Handler that uses github.com/stretchr/testify/mock.
-type MockHandlerWithHeaders struct {
-	mock.Mock
+// StrictRegistration makes shadowed expectations fail t instead of only logging a warning.
+func (m *mockHandler) StrictRegistration(t TestingT) {
+	m.tracker.setStrict(t)
+}
+
+// SetLogger makes warnings, such as those about shadowed expectations, go to l. See the WithLogger option.
+func (m *mockHandler) SetLogger(l Logger) {
+	m.tracker.setLogger(l)
+}
+
+// SoftAssertions makes requests matching no expectation get a 500 response and be reported by Verify, instead of
+// failing the test from the server's goroutine. See the SoftAssertions option.
+func (m *mockHandler) SoftAssertions() {
+	m.tracker.setSoft(&m.Mock)
+}
+
+// Verify reports the requests that matched no expectation in soft assertions mode, and asserts the expectations.
+func (m *mockHandler) Verify(t TestingT) bool {
+	return m.AssertExpectations(mockTestingT{t})
+}
+
+// AssertExpectations reports the requests that matched no expectation in soft assertions mode, and then asserts the
+// expectations like mock.Mock.AssertExpectations.
+func (m *mockHandler) AssertExpectations(t mock.TestingT) bool {
+	ok := m.tracker.reportMismatches(t)
+	return m.Mock.AssertExpectations(t) && ok
+}
+
+func (m *mockHandler) reportMismatches(t TestingT) bool {
+	return m.tracker.reportMismatches(t)
 }
 
 // Handle makes this implement the Handler interface.
-func (m *MockHandlerWithHeaders) Handle(method, path string, body []byte) Response {
-	args := m.Called(method, path, body)
+func (m *mockHandler) Handle(method, path string, body []byte) Response {
+	args := m.tracker.called(&m.Mock, "Handle", RequestInfo{Method: method, Path: path, Body: body}, method, path, body)
 	return args.Get(0).(Response)
 }
 
+// OnMatch registers fn to be called with every request matching the expectation call of this handler, before its
+// response is sent, so that tests can wait for the client to reach a given endpoint without polling. Unlike call.Run,
+// fn gets the whole request. It returns call for chaining.
+//
+//	refreshed := make(chan struct{}, 1)
+//	m.OnMatch(m.On("Handle", "POST", "/token", mock.Anything).Return(resp), func(httpmock.RequestInfo) {
+//		refreshed <- struct{}{}
+//	})
+func (m *mockHandler) OnMatch(call *mock.Call, fn func(req RequestInfo)) *mock.Call {
+	m.tracker.onMatch(call, fn)
+	return call
+}
+
+// MaxConcurrent makes t fail when more than max requests matching the expectation call of this handler are handled
+// at once, e.g. to verify that the client serializes token refreshes. A request counts as being handled while the
+// call's Run function runs, so simulate processing time there rather than with call.After or call.WaitUntil, which
+// delay requests before they count. Set the Run function first, as it is wrapped. It returns call for chaining.
+//
+//	m.MaxConcurrent(t, m.On("Handle", "POST", "/token", mock.Anything).Return(resp).Run(func(mock.Arguments) {
+//		time.Sleep(50 * time.Millisecond)
+//	}), 1)
+func (m *mockHandler) MaxConcurrent(t TestingT, call *mock.Call, max int) *mock.Call {
+	limitConcurrency(call, max, t)
+	return call
+}
+
+// QueueConcurrent makes requests matching the expectation call of this handler wait while max others are being
+// handled, like a downstream processing them one at a time when max is 1. Requests wait before the call's Run
+// function, see MaxConcurrent. It returns call for chaining.
+func (m *mockHandler) QueueConcurrent(call *mock.Call, max int) *mock.Call {
+	limitConcurrency(call, max, nil)
+	return call
+}
+
+// Reset clears the handler's expectations and recorded calls, see Server.Reset.
+func (m *mockHandler) Reset() {
+	resetMock(&m.Mock)
+	m.tracker.reset()
+}
+
+// Report summarizes how the handler's expectations were used, see Server.Report.
+func (m *mockHandler) Report() Report {
+	return m.tracker.report(&m.Mock)
+}
+
+// metricsCounts returns the usage of the handler's expectations and the number of unmatched requests, for ExposeMetrics.
+func (m *mockHandler) metricsCounts() ([]ExpectationUsage, int) {
+	return m.tracker.counts(&m.Mock)
+}
+
+// AssertHandleCalled asserts that the handler was called exactly times times for method and path, through any of its
+// Handle methods, e.g. m.AssertHandleCalled(t, "GET", "/object/12345", 2). Requests still being handled aren't
+// counted, so it is safe to call while others are in flight.
+func (m *mockHandler) AssertHandleCalled(t TestingT, method, path string, times int) bool {
+	return assertRouteCalled(t, &m.tracker, method, path, times)
+}
+
+// MockHandler is a httpmock.Handler that uses github.com/stretchr/testify/mock. It embeds a mock.Mock, so all of its
+// methods, such as AssertCalled, can be used as well.
+type MockHandler struct {
+	mockHandler
+}
+
+// MockHandlerWithHeaders is a httpmock.HandlerWithHeaders that uses github.com/stretchr/testify/mock, like
+// MockHandler.
+type MockHandlerWithHeaders struct {
+	mockHandler
+}
+
 // HandleWithHeaders makes this implement the HandlerWithHeaders interface.
 func (m *MockHandlerWithHeaders) HandleWithHeaders(method, path string, headers http.Header, body []byte) Response {
-	args := m.Called(method, path, headers, body)
+	req := RequestInfo{Method: method, Path: path, Header: headers, Body: body}
+	args := m.tracker.called(&m.Mock, "HandleWithHeaders", req, method, path, headers, body)
 	return args.Get(0).(Response)
 }
 
+// MockHandlerWithRequest is a httpmock.HandlerWithRequest that uses github.com/stretchr/testify/mock, like
+// MockHandler.
+type MockHandlerWithRequest struct {
+	mockHandler
+}
+
+// HandleRequest makes this implement the HandlerWithRequest interface.
+func (m *MockHandlerWithRequest) HandleRequest(req RequestInfo) Response {
+	args := m.tracker.called(&m.Mock, "HandleRequest", req, req)
+	return args.Get(0).(Response)
+}
+
+// assertRouteCalled asserts that r recorded exactly times requests for method and path, whichever Handler method they
+// went through. Requests still being handled aren't counted yet.
+func assertRouteCalled(t TestingT, r *tracker, method, path string, times int) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if count := r.handledCount(method, path); count != times {
+		t.Errorf("httpmock: expected %s %s to be handled %d times, but it was handled %d times",
+			method, path, times, count)
+		return false
+	}
+	return true
+}
+
+// resetMock clears the expectations and recorded calls of m. testify has no method for this, but both are exported.
+func resetMock(m *mock.Mock) {
+	mu := mockMutex(m)
+	mu.Lock()
+	defer mu.Unlock()
+	m.ExpectedCalls = nil
+	m.Calls = nil
+}
+
+// RequestMatcher returns a mock.MatchedBy func to check the RequestInfo argument of HandleRequest with the given
+// function. It is a shorthand for mock.MatchedBy(func(req httpmock.RequestInfo) bool { ... }).
+func RequestMatcher(fn func(req RequestInfo) bool) interface{} {
+	return mock.MatchedBy(fn)
+}
+
 // JSONMatcher returns a mock.MatchedBy func to check if the argument is the json form of the provided object.
 // See the github.com/stretchr/testify/mock documentation and example in httpmock.go.
 func JSONMatcher(o1 interface{}) interface{} {
@@ -64,6 +205,16 @@ func ToJSON(obj interface{}) []byte {
 	return data
 }
 
+// ToXML is a convenience function for converting an object to XML inline. It panics on failure, so should be used
+// only in test code.
+func ToXML(obj interface{}) []byte {
+	data, err := xml.Marshal(obj)
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal object %v: %v", obj, err))
+	}
+	return data
+}
+
 // HeaderMatcher matches the presence of a header named key that has a given value. Other headers
 // are allowed to exist and are not checked.
 func HeaderMatcher(key, value string) interface{} {
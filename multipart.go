@@ -0,0 +1,87 @@
+package httpmock
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+// MultipartBuilder builds multipart responses, such as the multipart/mixed responses of batch APIs or the
+// multipart/byteranges responses to range requests. Create one with NewMultipartMixed or NewByteRanges, add parts,
+// then call Response.
+type MultipartBuilder struct {
+	subtype  string
+	boundary string
+	parts    []multipartPart
+	status   int
+}
+
+type multipartPart struct {
+	header http.Header
+	body   []byte
+}
+
+// NewMultipartMixed returns a builder for a multipart/mixed response.
+//
+//	resp := httpmock.NewMultipartMixed().
+//		AddPart(http.Header{"Content-Type": {"application/json"}}, []byte(`{"id":1}`)).
+//		AddPart(http.Header{"Content-Type": {"application/json"}}, []byte(`{"id":2}`)).
+//		Response()
+func NewMultipartMixed() *MultipartBuilder {
+	return &MultipartBuilder{subtype: "mixed", status: http.StatusOK}
+}
+
+// NewByteRanges returns a builder for a 206 multipart/byteranges response serving ranges of content. Use AddRange to
+// add a part for each range.
+func NewByteRanges() *MultipartBuilder {
+	return &MultipartBuilder{subtype: "byteranges", status: http.StatusPartialContent}
+}
+
+// SetBoundary sets the boundary separating the parts, which is otherwise chosen randomly. This is useful for clients
+// that are expected to handle unusual boundaries.
+func (b *MultipartBuilder) SetBoundary(boundary string) *MultipartBuilder {
+	b.boundary = boundary
+	return b
+}
+
+// AddPart adds a part with the given headers and body.
+func (b *MultipartBuilder) AddPart(header http.Header, body []byte) *MultipartBuilder {
+	b.parts = append(b.parts, multipartPart{header: header, body: body})
+	return b
+}
+
+// AddRange adds a part holding content[start:end+1] (so end is inclusive, as in HTTP ranges), with the matching
+// Content-Range header and the given Content-Type.
+func (b *MultipartBuilder) AddRange(contentType string, content []byte, start, end int) *MultipartBuilder {
+	header := http.Header{
+		"Content-Type":  {contentType},
+		"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", start, end, len(content))},
+	}
+	return b.AddPart(header, content[start:end+1])
+}
+
+// Response renders the parts into a Response, with the Content-Type header carrying the boundary. It panics if the
+// boundary set with SetBoundary is invalid, so should be used only in test code.
+func (b *MultipartBuilder) Response() Response {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if b.boundary != "" {
+		if err := w.SetBoundary(b.boundary); err != nil {
+			panic(fmt.Sprintf("invalid multipart boundary %q: %v", b.boundary, err))
+		}
+	}
+	for _, part := range b.parts {
+		// Writing to a bytes.Buffer can't fail
+		pw, _ := w.CreatePart(textproto.MIMEHeader(part.header))
+		_, _ = pw.Write(part.body)
+	}
+	_ = w.Close()
+
+	return Response{
+		Status: b.status,
+		Header: http.Header{"Content-Type": {fmt.Sprintf("multipart/%s; boundary=%s", b.subtype, w.Boundary())}},
+		Body:   buf.Bytes(),
+	}
+}
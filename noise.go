@@ -0,0 +1,36 @@
+package httpmock
+
+import "net/http"
+
+// TelemetryPaths are the usual paths of health checks, metrics and tracing exports (OpenTelemetry, Zipkin and Jaeger),
+// for use with IgnorePaths.
+var TelemetryPaths = []string{
+	"/health", "/healthz", "/livez", "/readyz", "/ping", "/metrics",
+	"/v1/traces", "/v1/metrics", "/v1/logs", "/api/v2/spans", "/api/traces",
+}
+
+// IgnorePaths makes the server answer requests whose path (ignoring the query string) matches any of the glob patterns,
+// see PathGlobMatcher, with an empty 200 response, without calling the Handler or any middleware, nor recording them
+// in the history. This keeps background noise, such as the health checks and telemetry exports of the code under test,
+// from needing expectations.
+//
+//	s := httpmock.NewServer(downstream, httpmock.IgnorePaths(httpmock.TelemetryPaths...))
+func IgnorePaths(patterns ...string) Option {
+	return func(s *Server) {
+		for _, pattern := range patterns {
+			s.ignored = append(s.ignored, splitPath(pattern))
+		}
+	}
+}
+
+// ignore answers r if its path is ignored, reporting whether it was.
+func (s *Server) ignore(w http.ResponseWriter, r *http.Request) bool {
+	segments := splitPath(r.URL.Path)
+	for _, pattern := range s.ignored {
+		if globMatch(pattern, segments) {
+			w.WriteHeader(http.StatusOK)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnorePaths(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/api", mock.Anything).Return(Response{Status: 204})
+	s := NewServer(downstream, RecordHistory(), IgnorePaths(append(TelemetryPaths, "/internal/**")...))
+	defer s.Close()
+
+	for _, path := range []string{"/healthz", "/v1/traces", "/internal/debug/vars?x=1", "/api"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	downstream.AssertExpectations(t)
+	assert.Len(t, s.History(), 1)
+	assert.Equal(t, 1, s.TotalRequests())
+}
@@ -0,0 +1,51 @@
+package httpmock
+
+import "net/http"
+
+// Option configures a Server. Options are passed to NewServer or NewUnstartedServer.
+type Option func(*Server)
+
+// TestingT is the subset of *testing.T that httpmock needs to report errors to a test.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// ReportErrorsTo makes the server report errors that happen while serving requests (such as a Response.BodyObject
+// that can't be marshaled) to t, failing the test, instead of only logging them.
+func ReportErrorsTo(t TestingT) Option {
+	return func(s *Server) {
+		s.reportErrorsTo = t
+	}
+}
+
+// OnPanic sets the function called when the handler or middleware panics while serving req, with the value passed to
+// panic and the goroutine's stack trace. The panic is recovered and the client gets a 500 response either way. By
+// default, panics are reported like other errors, see ReportErrorsTo.
+//
+//	s := httpmock.NewServer(downstream, httpmock.OnPanic(func(req httpmock.RequestInfo, v interface{}, stack []byte) {
+//		t.Errorf("handler panicked on %s %s: %v\n%s", req.Method, req.Path, v, stack)
+//	}))
+func OnPanic(fn func(req RequestInfo, value interface{}, stack []byte)) Option {
+	return func(s *Server) {
+		s.onPanic = fn
+	}
+}
+
+// WithMiddleware adds middleware wrapping the handling of every request, see Middleware. Middleware runs in the order
+// given, the first one being the outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(s *Server) {
+		s.middleware = append(s.middleware, middleware...)
+	}
+}
+
+// WithDefaultHeaders makes the server add the given headers to every response that doesn't set them itself, see
+// DefaultHeaders. A default Content-Type also applies to responses with a BodyObject, instead of application/json.
+//
+//	s := httpmock.NewServer(downstream, httpmock.WithDefaultHeaders(http.Header{
+//		"Content-Type": {"application/json"},
+//		"Server":       {"upstream/1.0"},
+//	}))
+func WithDefaultHeaders(header http.Header) Option {
+	return WithMiddleware(DefaultHeaders(header))
+}
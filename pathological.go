@@ -0,0 +1,106 @@
+package httpmock
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GzipBomb returns size zero bytes compressed with gzip, which compresses them about a thousandfold, so that a small
+// body decompresses to gigabytes. Compressing takes about a second per gigabyte.
+func GzipBomb(size int64) []byte {
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	zeros := make([]byte, 64<<10)
+	for size > 0 {
+		n := int64(len(zeros))
+		if size < n {
+			n = size
+		}
+		_, _ = zw.Write(zeros[:n])
+		size -= n
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+// GzipBombResponse returns a Response whose body is a GzipBomb of size bytes, with Content-Encoding gzip, for testing
+// that clients limit how much they decompress.
+func GzipBombResponse(size int64) Response {
+	return Response{
+		Header: http.Header{
+			"Content-Type":     {"application/octet-stream"},
+			"Content-Encoding": {"gzip"},
+		},
+		Body: GzipBomb(size),
+	}
+}
+
+// OversizedBodyResponse returns a Response with the given status and a body of size bytes, declared in its
+// Content-Length, which is streamed to the client without being held in memory, for testing that clients limit how
+// much they read.
+func OversizedBodyResponse(status int, size int64) Response {
+	return Response{Hijack: func(conn net.Conn, rw *bufio.ReadWriter) {
+		writeHead(rw, status, http.Header{
+			"Content-Type":   {"application/octet-stream"},
+			"Content-Length": {strconv.FormatInt(size, 10)},
+		})
+		filler := bytes.Repeat([]byte{'a'}, 32<<10)
+		// The response can be served many times, so size must be left as is
+		for remaining := size; remaining > 0; {
+			n := int64(len(filler))
+			if remaining < n {
+				n = remaining
+			}
+			if _, err := rw.Write(filler[:n]); err != nil {
+				return
+			}
+			remaining -= n
+		}
+	}}
+}
+
+// EndlessBodyResponse returns a Response with the given status and headers whose chunked body repeats chunk until the
+// client closes the connection, for testing that clients time out or limit how much they read. The client must close
+// the connection for the server to stop writing.
+func EndlessBodyResponse(status int, header http.Header, chunk []byte) Response {
+	if len(chunk) == 0 {
+		panic("httpmock: EndlessBodyResponse needs a non-empty chunk")
+	}
+	return Response{Hijack: func(conn net.Conn, rw *bufio.ReadWriter) {
+		h := cloneHeader(header)
+		h.Set("Transfer-Encoding", "chunked")
+		writeHead(rw, status, h)
+		for {
+			fmt.Fprintf(rw, "%x\r\n", len(chunk))
+			rw.Write(chunk)
+			rw.WriteString("\r\n")
+			if err := rw.Flush(); err != nil {
+				return
+			}
+		}
+	}}
+}
+
+// OversizedHeaderResponse returns resp with the header name set to a value of size bytes, for testing that clients
+// limit the size of the response headers they read, like http.Transport.MaxResponseHeaderBytes.
+func OversizedHeaderResponse(resp Response, name string, size int) Response {
+	resp.Header = cloneHeader(resp.Header)
+	resp.Header.Set(name, strings.Repeat("a", size))
+	return resp
+}
+
+// writeHead writes the status line and the headers of a response to rw, for Hijack functions.
+func writeHead(rw *bufio.ReadWriter, status int, header http.Header) {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 %03d %s\r\n", status, http.StatusText(status))
+	_ = header.Write(rw)
+	rw.WriteString("\r\n")
+}
@@ -0,0 +1,87 @@
+package httpmock
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipBombResponse(t *testing.T) {
+	resp := GzipBombResponse(10 << 20)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Less(t, len(resp.Body), 20<<10)
+	zr, err := gzip.NewReader(bytes.NewReader(resp.Body))
+	require.NoError(t, err)
+	n, err := io.Copy(io.Discard, zr)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10<<20), n)
+}
+
+func TestOversizedBodyResponse(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/big", mock.Anything).Return(OversizedBodyResponse(http.StatusOK, 1<<20+3))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	// The same Response must be served in full every time
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(s.URL() + "/big")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1<<20+3), resp.ContentLength)
+		n, err := io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1<<20+3), n)
+		resp.Body.Close()
+	}
+}
+
+func TestEndlessBodyResponse(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/stream", mock.Anything).Return(
+		EndlessBodyResponse(http.StatusOK, http.Header{"Content-Type": {"text/plain"}}, []byte("data\n")))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	// Serve the same Response on two connections at once
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(s.URL() + "/stream")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+			n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1<<20), n)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOversizedHeaderResponse(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(
+		OversizedHeaderResponse(Response{Body: []byte("ok")}, "X-Padding", 64<<10))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	client := &http.Client{Transport: &http.Transport{MaxResponseHeaderBytes: 32 << 10}}
+	_, err := client.Get(s.URL() + "/")
+	assert.ErrorContains(t, err, "header")
+
+	resp, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, resp.Header.Get("X-Padding"), 64<<10)
+}
@@ -0,0 +1,245 @@
+package presets
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dankinder/httpmock"
+)
+
+// GitHub is a handler mimicking a small part of the GitHub REST API: the authenticated user, repositories, and the
+// issues of a repository, which can be listed, created, fetched and updated. Repositories are added with AddRepo.
+//
+// Responses carry the X-RateLimit-* headers, with the remaining count going down with every request, and errors use
+// GitHub's {"message": ..., "documentation_url": ...} shape.
+type GitHub struct {
+	// Token, if set, must be sent by clients in the Authorization header, as "token ..." or "Bearer ..."; other
+	// requests get a 401.
+	Token string
+	// Login is the login of the authenticated user (default: "octocat").
+	Login string
+	// RateLimit is the number of requests allowed before requests get a 403 (default: 5000).
+	RateLimit int
+
+	mu       sync.Mutex
+	repos    map[string]*gitHubRepo
+	nextID   int64
+	requests int
+}
+
+// GitHubIssue is an issue served by the GitHub preset.
+type GitHubIssue struct {
+	ID        int64      `json:"id"`
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	User      GitHubUser `json:"user"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// GitHubUser is the user object embedded in GitHub preset responses.
+type GitHubUser struct {
+	Login string `json:"login"`
+	ID    int64  `json:"id"`
+}
+
+type gitHubRepo struct {
+	id     int64
+	owner  string
+	name   string
+	issues []*GitHubIssue
+}
+
+// NewGitHub returns a GitHub preset without any repositories.
+func NewGitHub() *GitHub {
+	return &GitHub{repos: map[string]*gitHubRepo{}}
+}
+
+// AddRepo adds an empty repository.
+func (g *GitHub) AddRepo(owner, name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextID++
+	g.repos[owner+"/"+name] = &gitHubRepo{id: g.nextID, owner: owner, name: name}
+}
+
+// Issues returns a copy of the issues of a repository, for assertions.
+func (g *GitHub) Issues(owner, name string) []GitHubIssue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	repo, ok := g.repos[owner+"/"+name]
+	if !ok {
+		return nil
+	}
+	issues := make([]GitHubIssue, len(repo.issues))
+	for i, issue := range repo.issues {
+		issues[i] = *issue
+	}
+	return issues
+}
+
+// Handle makes this implement the Handler interface.
+func (g *GitHub) Handle(method, path string, body []byte) httpmock.Response {
+	return g.HandleWithHeaders(method, path, http.Header{}, body)
+}
+
+// HandleWithHeaders makes this implement the HandlerWithHeaders interface.
+func (g *GitHub) HandleWithHeaders(method, path string, headers http.Header, body []byte) httpmock.Response {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	limit := g.RateLimit
+	if limit == 0 {
+		limit = 5000
+	}
+	g.requests++
+	remaining := limit - g.requests
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	var resp httpmock.Response
+	switch {
+	case g.Token != "" && bearerToken(headers.Get("Authorization"), "token", "Bearer") != g.Token:
+		resp = gitHubError(http.StatusUnauthorized, "Bad credentials")
+	case g.requests > limit:
+		resp = gitHubError(http.StatusForbidden, "API rate limit exceeded")
+	default:
+		resp = g.route(method, path, body)
+	}
+
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp.Header.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	return resp
+}
+
+func (g *GitHub) route(method, path string, body []byte) httpmock.Response {
+	segments, query := splitRequestURI(path)
+	switch {
+	case len(segments) == 1 && segments[0] == "user" && method == http.MethodGet:
+		return jsonResponse(http.StatusOK, g.user())
+	case len(segments) < 3 || segments[0] != "repos":
+		return gitHubError(http.StatusNotFound, "Not Found")
+	}
+
+	repo, ok := g.repos[segments[1]+"/"+segments[2]]
+	if !ok {
+		return gitHubError(http.StatusNotFound, "Not Found")
+	}
+	switch {
+	case len(segments) == 3 && method == http.MethodGet:
+		return jsonResponse(http.StatusOK, map[string]interface{}{
+			"id":        repo.id,
+			"name":      repo.name,
+			"full_name": repo.owner + "/" + repo.name,
+			"owner":     GitHubUser{Login: repo.owner, ID: 1},
+			"private":   false,
+		})
+	case len(segments) == 4 && segments[3] == "issues" && method == http.MethodGet:
+		state := query.Get("state")
+		if state == "" {
+			state = "open"
+		}
+		// Copy the issues, as the response is marshaled after the lock is released
+		issues := []GitHubIssue{}
+		for _, issue := range repo.issues {
+			if state == "all" || issue.State == state {
+				issues = append(issues, *issue)
+			}
+		}
+		return jsonResponse(http.StatusOK, issues)
+	case len(segments) == 4 && segments[3] == "issues" && method == http.MethodPost:
+		var params struct {
+			Title string `json:"title"`
+			Body  string `json:"body"`
+		}
+		if err := json.Unmarshal(body, &params); err != nil {
+			return gitHubError(http.StatusBadRequest, "Problems parsing JSON")
+		}
+		if params.Title == "" {
+			return gitHubValidationError("Issue", "title", "missing_field")
+		}
+		g.nextID++
+		now := time.Now().UTC().Truncate(time.Second)
+		issue := &GitHubIssue{
+			ID:        g.nextID,
+			Number:    len(repo.issues) + 1,
+			Title:     params.Title,
+			Body:      params.Body,
+			State:     "open",
+			User:      g.user(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		repo.issues = append(repo.issues, issue)
+		return jsonResponse(http.StatusCreated, *issue)
+	case len(segments) == 5 && segments[3] == "issues":
+		number, err := strconv.Atoi(segments[4])
+		if err != nil || number < 1 || number > len(repo.issues) {
+			return gitHubError(http.StatusNotFound, "Not Found")
+		}
+		issue := repo.issues[number-1]
+		switch method {
+		case http.MethodGet:
+			return jsonResponse(http.StatusOK, *issue)
+		case http.MethodPatch:
+			var params struct {
+				Title *string `json:"title"`
+				Body  *string `json:"body"`
+				State *string `json:"state"`
+			}
+			if err := json.Unmarshal(body, &params); err != nil {
+				return gitHubError(http.StatusBadRequest, "Problems parsing JSON")
+			}
+			if params.State != nil && *params.State != "open" && *params.State != "closed" {
+				return gitHubValidationError("Issue", "state", "invalid")
+			}
+			if params.Title != nil {
+				issue.Title = *params.Title
+			}
+			if params.Body != nil {
+				issue.Body = *params.Body
+			}
+			if params.State != nil {
+				issue.State = *params.State
+			}
+			issue.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+			return jsonResponse(http.StatusOK, *issue)
+		}
+	}
+	return gitHubError(http.StatusNotFound, "Not Found")
+}
+
+func (g *GitHub) user() GitHubUser {
+	login := g.Login
+	if login == "" {
+		login = "octocat"
+	}
+	return GitHubUser{Login: login, ID: 1}
+}
+
+func gitHubError(status int, message string) httpmock.Response {
+	return jsonResponse(status, map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+func gitHubValidationError(resource, field, code string) httpmock.Response {
+	return jsonResponse(http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": "Validation Failed",
+		"errors": []map[string]string{
+			{"resource": resource, "field": field, "code": code},
+		},
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
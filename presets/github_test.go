@@ -0,0 +1,59 @@
+package presets
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHub(t *testing.T) {
+	gh := NewGitHub()
+	gh.Token = "secret"
+	gh.AddRepo("octocat", "hello-world")
+
+	s := httpmock.NewServer(gh)
+	defer s.Close()
+
+	do := func(method, path, body string) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest(method, s.URL()+path, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "token secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var obj map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&obj)
+		return resp, obj
+	}
+
+	resp, obj := do("POST", "/repos/octocat/hello-world/issues", `{"title": "Found a bug", "body": "It broke"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, float64(1), obj["number"])
+	assert.Equal(t, "4999", resp.Header.Get("X-RateLimit-Remaining"))
+
+	resp, obj = do("POST", "/repos/octocat/hello-world/issues", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Equal(t, "Validation Failed", obj["message"])
+
+	resp, obj = do("PATCH", "/repos/octocat/hello-world/issues/1", `{"state": "closed"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "closed", obj["state"])
+
+	resp, _ = do("GET", "/repos/octocat/missing", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	issues := gh.Issues("octocat", "hello-world")
+	require.Len(t, issues, 1)
+	assert.Equal(t, "Found a bug", issues[0].Title)
+	assert.Equal(t, "octocat", issues[0].User.Login)
+
+	resp, err := http.Get(s.URL() + "/user")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
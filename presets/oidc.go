@@ -0,0 +1,370 @@
+package presets
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dankinder/httpmock"
+)
+
+// OIDCProvider is a handler serving a generic OpenID Connect discovery document at /.well-known/openid-configuration
+// and a JWKS at /jwks holding the public half of a keypair generated for the provider. Tokens signed with the key can
+// be minted with MintToken, or requested from the /token endpoint with the client_credentials grant, so code that
+// validates tokens can be tested hermetically.
+//
+// It also runs the authorization code flow of OAuth 2.0 and OpenID Connect, carrying its state across calls:
+// /authorize logs the user in without any prompt and redirects to the client's redirect_uri with a code, which
+// /token exchanges once for an access token, an ID token and a refresh token (verifying the PKCE code_verifier if
+// the client sent a code_challenge), and /userinfo returns the user's claims to requests bearing a token the provider
+// issued. Set Issuer to the server's URL once it is started, since the discovery document and the tokens refer to
+// it:
+//
+//	p := presets.NewOIDCProvider()
+//	s := httpmock.NewServer(p)
+//	defer s.Close()
+//	p.Issuer = s.URL()
+type OIDCProvider struct {
+	// Issuer is the issuer identifier, which the endpoint URLs of the discovery document are based on.
+	Issuer string
+	// Subject is the ID of the user that /authorize logs in (default: "user-1").
+	Subject string
+	// UserClaims are the claims about the user returned by /userinfo and added to ID tokens, e.g. "email" or "name".
+	UserClaims map[string]interface{}
+
+	key   *rsa.PrivateKey
+	keyID string
+
+	mu            sync.Mutex
+	codes         map[string]oidcGrant
+	accessTokens  map[string]oidcGrant
+	refreshTokens map[string]oidcGrant
+}
+
+// oidcGrant is what an authorization code or token was granted for.
+type oidcGrant struct {
+	clientID, redirectURI, scope, nonce string
+	codeChallenge, codeChallengeMethod  string
+	subject                             string
+}
+
+// NewOIDCProvider returns an OIDCProvider with a newly generated RSA key. It panics if the key can't be generated.
+func NewOIDCProvider() *OIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("failed to generate OIDC provider key: " + err.Error())
+	}
+	// Derive the key ID from the public key, like many providers do
+	sum := sha256.Sum256(key.PublicKey.N.Bytes())
+	return &OIDCProvider{key: key, keyID: base64.RawURLEncoding.EncodeToString(sum[:8])}
+}
+
+// PublicKey returns the provider's public key.
+func (p *OIDCProvider) PublicKey() *rsa.PublicKey {
+	return &p.key.PublicKey
+}
+
+// KeyID returns the ID of the provider's key, the "kid" of its JWK.
+func (p *OIDCProvider) KeyID() string {
+	return p.keyID
+}
+
+// Reset forgets the codes and tokens issued so far, see Server.Reset. Tokens minted with MintToken stay valid JWTs,
+// but /userinfo no longer accepts them.
+func (p *OIDCProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes = nil
+	p.accessTokens = nil
+	p.refreshTokens = nil
+}
+
+// Handle makes this implement the Handler interface.
+func (p *OIDCProvider) Handle(method, path string, body []byte) httpmock.Response {
+	return p.HandleWithHeaders(method, path, http.Header{}, body)
+}
+
+// HandleWithHeaders makes this implement the HandlerWithHeaders interface.
+func (p *OIDCProvider) HandleWithHeaders(method, path string, headers http.Header, body []byte) httpmock.Response {
+	segments, query := splitRequestURI(path)
+	route := strings.Join(segments, "/")
+	if route == "token" && method == http.MethodPost {
+		return p.token(body)
+	}
+	if route == "userinfo" && (method == http.MethodGet || method == http.MethodPost) {
+		return p.userinfo(headers)
+	}
+	if method != http.MethodGet {
+		return httpmock.Response{Status: http.StatusMethodNotAllowed}
+	}
+	switch route {
+	case "authorize":
+		return p.authorize(query)
+	case ".well-known/openid-configuration":
+		issuer := strings.TrimSuffix(p.Issuer, "/")
+		return jsonResponse(http.StatusOK, map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"userinfo_endpoint":                     issuer + "/userinfo",
+			"jwks_uri":                              issuer + "/jwks",
+			"response_types_supported":              []string{"code", "id_token", "token id_token"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"grant_types_supported":                 []string{"authorization_code", "refresh_token", "client_credentials"},
+			"code_challenge_methods_supported":      []string{"S256", "plain"},
+			"scopes_supported":                      []string{"openid", "profile", "email"},
+		})
+	case "jwks":
+		return jsonResponse(http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"kid": p.keyID,
+				"n":   base64.RawURLEncoding.EncodeToString(p.key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.PublicKey.E)).Bytes()),
+			}},
+		})
+	}
+	return httpmock.Response{Status: http.StatusNotFound}
+}
+
+// MintToken returns a JWT with the given claims, signed by the provider with RS256. The "iss", "iat" and "exp" claims
+// default to the provider's Issuer, the current time and an hour later, unless claims sets them; set a past "exp" to
+// test the handling of expired tokens. It panics if the claims can't be marshaled.
+//
+//	token := p.MintToken(map[string]interface{}{"sub": "user-1", "aud": "my-api"})
+func (p *OIDCProvider) MintToken(claims map[string]interface{}) string {
+	now := time.Now()
+	all := map[string]interface{}{
+		"iss": p.Issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+
+	header := base64.RawURLEncoding.EncodeToString(httpmock.ToJSON(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": p.keyID,
+	}))
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(httpmock.ToJSON(all))
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, sum[:])
+	if err != nil {
+		panic("failed to sign token: " + err.Error())
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// authorize serves the /authorize endpoint, logging the user in and redirecting to the client with a code.
+func (p *OIDCProvider) authorize(query url.Values) httpmock.Response {
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() || query.Get("client_id") == "" {
+		// Per RFC 6749, errors about the client or redirect URI must not be redirected
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+	}
+	params := url.Values{}
+	if state := query.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	method := query.Get("code_challenge_method")
+	if query.Get("response_type") != "code" {
+		params.Set("error", "unsupported_response_type")
+	} else if method != "" && method != "S256" && method != "plain" {
+		params.Set("error", "invalid_request")
+	} else {
+		if method == "" && query.Get("code_challenge") != "" {
+			method = "plain"
+		}
+		code := randomToken()
+		p.mu.Lock()
+		if p.codes == nil {
+			p.codes = make(map[string]oidcGrant)
+		}
+		p.codes[code] = oidcGrant{
+			clientID:            query.Get("client_id"),
+			redirectURI:         query.Get("redirect_uri"),
+			scope:               query.Get("scope"),
+			nonce:               query.Get("nonce"),
+			codeChallenge:       query.Get("code_challenge"),
+			codeChallengeMethod: method,
+			subject:             p.subject(),
+		}
+		p.mu.Unlock()
+		params.Set("code", code)
+	}
+	q := redirectURI.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	redirectURI.RawQuery = q.Encode()
+	return httpmock.Response{Status: http.StatusFound, Header: http.Header{"Location": {redirectURI.String()}}}
+}
+
+// token serves the /token endpoint.
+func (p *OIDCProvider) token(body []byte) httpmock.Response {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+	}
+	switch form.Get("grant_type") {
+	case "client_credentials":
+		return p.clientCredentials(form)
+	case "authorization_code":
+		p.mu.Lock()
+		grant, ok := p.codes[form.Get("code")]
+		// Codes can only be used once
+		delete(p.codes, form.Get("code"))
+		p.mu.Unlock()
+		if !ok || grant.redirectURI != form.Get("redirect_uri") ||
+			(form.Get("client_id") != "" && grant.clientID != form.Get("client_id")) ||
+			!verifyCodeChallenge(grant, form.Get("code_verifier")) {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		}
+		return p.grantTokens(grant, true)
+	case "refresh_token":
+		p.mu.Lock()
+		grant, ok := p.refreshTokens[form.Get("refresh_token")]
+		p.mu.Unlock()
+		if !ok {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		}
+		return p.grantTokens(grant, false)
+	default:
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+	}
+}
+
+// clientCredentials grants an access token whose subject is the client ID.
+func (p *OIDCProvider) clientCredentials(form url.Values) httpmock.Response {
+	clientID := form.Get("client_id")
+	if clientID == "" {
+		return jsonResponse(http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+	}
+	claims := map[string]interface{}{"sub": clientID, "client_id": clientID}
+	if scope := form.Get("scope"); scope != "" {
+		claims["scope"] = scope
+	}
+	accessToken := p.MintToken(claims)
+	p.mu.Lock()
+	if p.accessTokens == nil {
+		p.accessTokens = make(map[string]oidcGrant)
+	}
+	p.accessTokens[accessToken] = oidcGrant{clientID: clientID, scope: form.Get("scope"), subject: clientID}
+	p.mu.Unlock()
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+// grantTokens issues an access token for grant, with an ID token if the openid scope was requested, and with a new
+// refresh token if refresh is set.
+func (p *OIDCProvider) grantTokens(grant oidcGrant, refresh bool) httpmock.Response {
+	claims := map[string]interface{}{"sub": grant.subject, "client_id": grant.clientID}
+	if grant.scope != "" {
+		claims["scope"] = grant.scope
+	}
+	resp := map[string]interface{}{
+		"access_token": p.MintToken(claims),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	}
+	if grant.scope != "" {
+		resp["scope"] = grant.scope
+	}
+	if hasScope(grant.scope, "openid") {
+		idClaims := map[string]interface{}{"sub": grant.subject, "aud": grant.clientID}
+		if grant.nonce != "" {
+			idClaims["nonce"] = grant.nonce
+		}
+		for k, v := range p.UserClaims {
+			idClaims[k] = v
+		}
+		resp["id_token"] = p.MintToken(idClaims)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.accessTokens == nil {
+		p.accessTokens = make(map[string]oidcGrant)
+	}
+	p.accessTokens[resp["access_token"].(string)] = grant
+	if refresh {
+		refreshToken := randomToken()
+		if p.refreshTokens == nil {
+			p.refreshTokens = make(map[string]oidcGrant)
+		}
+		p.refreshTokens[refreshToken] = grant
+		resp["refresh_token"] = refreshToken
+	}
+	return jsonResponse(http.StatusOK, resp)
+}
+
+// userinfo serves the /userinfo endpoint, returning the claims about the user a bearer token was issued for.
+func (p *OIDCProvider) userinfo(headers http.Header) httpmock.Response {
+	p.mu.Lock()
+	grant, ok := p.accessTokens[bearerToken(headers.Get("Authorization"), "Bearer")]
+	p.mu.Unlock()
+	if !ok {
+		return httpmock.Response{
+			Status: http.StatusUnauthorized,
+			Header: http.Header{"WWW-Authenticate": {`Bearer error="invalid_token"`}},
+		}
+	}
+	claims := map[string]interface{}{}
+	for k, v := range p.UserClaims {
+		claims[k] = v
+	}
+	claims["sub"] = grant.subject
+	return jsonResponse(http.StatusOK, claims)
+}
+
+func (p *OIDCProvider) subject() string {
+	if p.Subject == "" {
+		return "user-1"
+	}
+	return p.Subject
+}
+
+// verifyCodeChallenge checks the PKCE code verifier of a token request against the challenge of its grant, if any.
+func verifyCodeChallenge(grant oidcGrant, verifier string) bool {
+	switch grant.codeChallengeMethod {
+	case "":
+		return true
+	case "S256":
+		sum := sha256.Sum256([]byte(verifier))
+		return base64.RawURLEncoding.EncodeToString(sum[:]) == grant.codeChallenge
+	default:
+		return verifier == grant.codeChallenge
+	}
+}
+
+func hasScope(scope, name string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// randomToken returns an opaque random token, for codes and refresh tokens.
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
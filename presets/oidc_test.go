@@ -0,0 +1,211 @@
+package presets
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCProviderDiscovery(t *testing.T) {
+	p := NewOIDCProvider()
+	s := httpmock.NewServer(p)
+	defer s.Close()
+	p.Issuer = s.URL()
+
+	var config struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	resp, err := http.Get(s.URL() + "/.well-known/openid-configuration")
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&config))
+	resp.Body.Close()
+	assert.Equal(t, s.URL(), config.Issuer)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	resp, err = http.Get(config.JWKSURI)
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+	resp.Body.Close()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, p.KeyID(), jwks.Keys[0]["kid"])
+	assert.Equal(t, "AQAB", jwks.Keys[0]["e"])
+}
+
+func TestOIDCProviderMintToken(t *testing.T) {
+	p := NewOIDCProvider()
+	s := httpmock.NewServer(p)
+	defer s.Close()
+	p.Issuer = s.URL()
+
+	verify := func(token string) map[string]interface{} {
+		parts := strings.Split(token, ".")
+		require.Len(t, parts, 3)
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		require.NoError(t, rsa.VerifyPKCS1v15(p.PublicKey(), crypto.SHA256, sum[:], sig))
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		return claims
+	}
+
+	claims := verify(p.MintToken(map[string]interface{}{"sub": "user-1", "exp": 0}))
+	assert.Equal(t, "user-1", claims["sub"])
+	assert.Equal(t, s.URL(), claims["iss"])
+	assert.Equal(t, float64(0), claims["exp"])
+
+	resp, err := http.PostForm(s.URL()+"/token", url.Values{"grant_type": {"client_credentials"}, "client_id": {"billing"}})
+	require.NoError(t, err)
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+	resp.Body.Close()
+	assert.Equal(t, "billing", verify(token.AccessToken)["sub"])
+}
+
+func TestOIDCProviderAuthorizationCodeFlow(t *testing.T) {
+	p := NewOIDCProvider()
+	p.Subject = "alice"
+	p.UserClaims = map[string]interface{}{"email": "alice@example.com"}
+	s := httpmock.NewServer(p)
+	defer s.Close()
+	p.Issuer = s.URL()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	resp, err := client.Get(s.URL() + "/authorize?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"web"},
+		"redirect_uri":          {"https://app.example.com/callback?from=login"},
+		"scope":                 {"openid email"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "app.example.com", location.Host)
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+	assert.Equal(t, "login", location.Query().Get("from"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	exchange := func(form url.Values) (int, map[string]interface{}) {
+		resp, err := http.PostForm(s.URL()+"/token", form)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {"https://app.example.com/callback?from=login"},
+		"client_id":     {"web"},
+		"code_verifier": {"wrong"},
+	}
+	status, body := exchange(form)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	// The failed attempt used up the code
+	status, _ = exchange(form)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	resp, err = client.Get(s.URL() + "/authorize?response_type=code&client_id=web&redirect_uri=" +
+		url.QueryEscape("https://app.example.com/callback"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	location, err = url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	status, body = exchange(url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {location.Query().Get("code")},
+		"redirect_uri": {"https://app.example.com/callback"},
+	})
+	require.Equal(t, http.StatusOK, status)
+	assert.Nil(t, body["id_token"], "an ID token should only be issued for the openid scope")
+	accessToken := body["access_token"].(string)
+	refreshToken := body["refresh_token"].(string)
+
+	userinfo := func(token string) (int, map[string]interface{}) {
+		req, err := http.NewRequest("GET", s.URL()+"/userinfo", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var claims map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&claims)
+		return resp.StatusCode, claims
+	}
+	status, claims := userinfo(accessToken)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "alice", claims["sub"])
+	assert.Equal(t, "alice@example.com", claims["email"])
+	status, _ = userinfo("forged")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, body = exchange(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	require.Equal(t, http.StatusOK, status)
+	status, _ = userinfo(body["access_token"].(string))
+	assert.Equal(t, http.StatusOK, status)
+
+	s.Reset()
+	status, _ = userinfo(accessToken)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestOIDCProviderIDToken(t *testing.T) {
+	p := NewOIDCProvider()
+	s := httpmock.NewServer(p)
+	defer s.Close()
+	p.Issuer = s.URL()
+
+	resp := p.Handle("GET", "/authorize?response_type=code&client_id=web&scope=openid&nonce=abc&redirect_uri="+
+		url.QueryEscape("https://app.example.com/cb"), nil)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	resp = p.Handle("POST", "/token", []byte(url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {location.Query().Get("code")},
+		"redirect_uri": {"https://app.example.com/cb"},
+	}.Encode()))
+	idToken := resp.BodyObject.(map[string]interface{})["id_token"].(string)
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(idToken, ".")[1])
+	require.NoError(t, err)
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	assert.Equal(t, "user-1", claims["sub"])
+	assert.Equal(t, "web", claims["aud"])
+	assert.Equal(t, "abc", claims["nonce"])
+
+	resp = p.Handle("GET", "/authorize?response_type=token&client_id=web&state=s&redirect_uri="+
+		url.QueryEscape("https://app.example.com/cb"), nil)
+	assert.Equal(t, "https://app.example.com/cb?error=unsupported_response_type&state=s", resp.Header.Get("Location"))
+	resp = p.Handle("GET", "/authorize?response_type=code&client_id=web&redirect_uri=/relative", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.Status)
+}
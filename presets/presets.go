@@ -0,0 +1,49 @@
+/*
+Package presets provides ready-made httpmock handlers mimicking the shape of widely used APIs, to get tests of
+integration code going without writing a stub for every endpoint.
+
+The presets keep their state in memory and implement enough of each API for typical client code: the JSON shapes,
+status codes and error formats match the real services, but many endpoints and parameters aren't supported.
+
+	gh := presets.NewGitHub()
+	gh.AddRepo("octocat", "hello-world")
+
+	s := httpmock.NewServer(gh)
+	defer s.Close()
+
+	// Point the GitHub client under test at s.URL()
+*/
+package presets
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dankinder/httpmock"
+)
+
+// jsonResponse returns a response with obj marshaled as its JSON body.
+func jsonResponse(status int, obj interface{}) httpmock.Response {
+	return httpmock.Response{Status: status, BodyObject: obj}
+}
+
+// splitRequestURI splits a request URI into its path segments, ignoring the leading slash, and its query.
+func splitRequestURI(requestURI string) ([]string, url.Values) {
+	p, rawQuery, _ := strings.Cut(requestURI, "?")
+	query, _ := url.ParseQuery(rawQuery)
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil, query
+	}
+	return strings.Split(p, "/"), query
+}
+
+// bearerToken returns the token of an Authorization header using the given schemes, e.g. "Bearer".
+func bearerToken(authorization string, schemes ...string) string {
+	for _, scheme := range schemes {
+		if len(authorization) > len(scheme) && strings.EqualFold(authorization[:len(scheme)+1], scheme+" ") {
+			return strings.TrimSpace(authorization[len(scheme)+1:])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,236 @@
+package presets
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dankinder/httpmock"
+)
+
+// Stripe is a handler mimicking a small part of the Stripe API: customers and payment intents, with form-encoded
+// requests, Stripe's error shape and Idempotency-Key handling. Payment intents using the payment method
+// "pm_card_chargeDeclined" fail to confirm with a card_declined error, like in Stripe's test mode.
+type Stripe struct {
+	// APIKey, if set, must be sent by clients as a bearer token or the basic auth username; other requests get a 401.
+	APIKey string
+
+	mu          sync.Mutex
+	customers   map[string]StripeCustomer
+	intents     map[string]*StripePaymentIntent
+	idempotency map[string]httpmock.Response
+	nextID      int
+}
+
+// StripeCustomer is a customer served by the Stripe preset.
+type StripeCustomer struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Email   string `json:"email,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Created int64  `json:"created"`
+}
+
+// StripePaymentIntent is a payment intent served by the Stripe preset.
+type StripePaymentIntent struct {
+	ID            string `json:"id"`
+	Object        string `json:"object"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Customer      string `json:"customer,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty"`
+	Status        string `json:"status"`
+	Created       int64  `json:"created"`
+}
+
+// NewStripe returns a Stripe preset without any objects.
+func NewStripe() *Stripe {
+	return &Stripe{
+		customers:   map[string]StripeCustomer{},
+		intents:     map[string]*StripePaymentIntent{},
+		idempotency: map[string]httpmock.Response{},
+	}
+}
+
+// PaymentIntents returns a copy of all payment intents, for assertions.
+func (s *Stripe) PaymentIntents() []StripePaymentIntent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	intents := make([]StripePaymentIntent, 0, len(s.intents))
+	for _, pi := range s.intents {
+		intents = append(intents, *pi)
+	}
+	return intents
+}
+
+// Handle makes this implement the Handler interface.
+func (s *Stripe) Handle(method, path string, body []byte) httpmock.Response {
+	return s.HandleWithHeaders(method, path, http.Header{}, body)
+}
+
+// HandleWithHeaders makes this implement the HandlerWithHeaders interface.
+func (s *Stripe) HandleWithHeaders(method, path string, headers http.Header, body []byte) httpmock.Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.APIKey != "" && s.apiKey(headers) != s.APIKey {
+		return stripeError(http.StatusUnauthorized, "invalid_request_error", "", "", "Invalid API Key provided.")
+	}
+
+	key := headers.Get("Idempotency-Key")
+	if key != "" && method == http.MethodPost {
+		if resp, ok := s.idempotency[key]; ok {
+			resp.Header = http.Header{"Idempotent-Replayed": {"true"}}
+			return resp
+		}
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return stripeError(http.StatusBadRequest, "invalid_request_error", "", "", "Invalid request body.")
+	}
+	resp := s.route(method, path, form)
+	if key != "" && method == http.MethodPost {
+		s.idempotency[key] = resp
+	}
+	return resp
+}
+
+func (s *Stripe) apiKey(headers http.Header) string {
+	if token := bearerToken(headers.Get("Authorization"), "Bearer"); token != "" {
+		return token
+	}
+	r := http.Request{Header: headers}
+	user, _, _ := r.BasicAuth()
+	return user
+}
+
+func (s *Stripe) route(method, path string, form url.Values) httpmock.Response {
+	segments, _ := splitRequestURI(path)
+	if len(segments) < 2 || segments[0] != "v1" {
+		return stripeError(http.StatusNotFound, "invalid_request_error", "", "", "Unrecognized request URL.")
+	}
+
+	switch {
+	case len(segments) == 2 && segments[1] == "customers" && method == http.MethodPost:
+		c := StripeCustomer{
+			ID:      s.newID("cus"),
+			Object:  "customer",
+			Email:   form.Get("email"),
+			Name:    form.Get("name"),
+			Created: time.Now().Unix(),
+		}
+		s.customers[c.ID] = c
+		return jsonResponse(http.StatusOK, c)
+	case len(segments) == 3 && segments[1] == "customers" && method == http.MethodGet:
+		c, ok := s.customers[segments[2]]
+		if !ok {
+			return stripeMissing("customer", segments[2])
+		}
+		return jsonResponse(http.StatusOK, c)
+	case len(segments) == 2 && segments[1] == "payment_intents" && method == http.MethodPost:
+		return s.createPaymentIntent(form)
+	case len(segments) >= 3 && segments[1] == "payment_intents":
+		pi, ok := s.intents[segments[2]]
+		if !ok {
+			return stripeMissing("payment_intent", segments[2])
+		}
+		switch {
+		case len(segments) == 3 && method == http.MethodGet:
+			return jsonResponse(http.StatusOK, *pi)
+		case len(segments) == 4 && segments[3] == "confirm" && method == http.MethodPost:
+			if pm := form.Get("payment_method"); pm != "" {
+				pi.PaymentMethod = pm
+			}
+			return s.confirm(pi)
+		case len(segments) == 4 && segments[3] == "cancel" && method == http.MethodPost:
+			if pi.Status == "succeeded" {
+				return stripeError(http.StatusBadRequest, "invalid_request_error", "payment_intent_unexpected_state", "",
+					"You cannot cancel this PaymentIntent because it has a status of succeeded.")
+			}
+			pi.Status = "canceled"
+			return jsonResponse(http.StatusOK, *pi)
+		}
+	}
+	return stripeError(http.StatusNotFound, "invalid_request_error", "", "", "Unrecognized request URL.")
+}
+
+func (s *Stripe) createPaymentIntent(form url.Values) httpmock.Response {
+	amount, err := strconv.ParseInt(form.Get("amount"), 10, 64)
+	switch {
+	case form.Get("amount") == "":
+		return stripeError(http.StatusBadRequest, "invalid_request_error", "parameter_missing", "amount",
+			"Missing required param: amount.")
+	case err != nil || amount < 1:
+		return stripeError(http.StatusBadRequest, "invalid_request_error", "parameter_invalid_integer", "amount",
+			"Invalid integer: "+form.Get("amount"))
+	case form.Get("currency") == "":
+		return stripeError(http.StatusBadRequest, "invalid_request_error", "parameter_missing", "currency",
+			"Missing required param: currency.")
+	}
+	if customer := form.Get("customer"); customer != "" {
+		if _, ok := s.customers[customer]; !ok {
+			return stripeMissing("customer", customer)
+		}
+	}
+
+	pi := &StripePaymentIntent{
+		ID:            s.newID("pi"),
+		Object:        "payment_intent",
+		Amount:        amount,
+		Currency:      form.Get("currency"),
+		Customer:      form.Get("customer"),
+		PaymentMethod: form.Get("payment_method"),
+		Status:        "requires_payment_method",
+		Created:       time.Now().Unix(),
+	}
+	if pi.PaymentMethod != "" {
+		pi.Status = "requires_confirmation"
+	}
+	s.intents[pi.ID] = pi
+	if form.Get("confirm") == "true" {
+		return s.confirm(pi)
+	}
+	return jsonResponse(http.StatusOK, *pi)
+}
+
+// confirm confirms a payment intent, declining it if it uses the declining test payment method.
+func (s *Stripe) confirm(pi *StripePaymentIntent) httpmock.Response {
+	switch {
+	case pi.Status != "requires_confirmation" && pi.Status != "requires_payment_method":
+		return stripeError(http.StatusBadRequest, "invalid_request_error", "payment_intent_unexpected_state", "",
+			"You cannot confirm this PaymentIntent because it has a status of "+pi.Status+".")
+	case pi.PaymentMethod == "":
+		return stripeError(http.StatusBadRequest, "invalid_request_error", "payment_intent_unexpected_state", "",
+			"You cannot confirm this PaymentIntent because it's missing a payment method.")
+	case pi.PaymentMethod == "pm_card_chargeDeclined":
+		pi.Status = "requires_payment_method"
+		return stripeError(http.StatusPaymentRequired, "card_error", "card_declined", "", "Your card was declined.")
+	}
+	pi.Status = "succeeded"
+	return jsonResponse(http.StatusOK, *pi)
+}
+
+func (s *Stripe) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s_%014d", prefix, s.nextID)
+}
+
+func stripeMissing(object, id string) httpmock.Response {
+	return stripeError(http.StatusNotFound, "invalid_request_error", "resource_missing", "id",
+		fmt.Sprintf("No such %s: '%s'", object, id))
+}
+
+func stripeError(status int, errType, code, param, message string) httpmock.Response {
+	e := map[string]string{"type": errType, "message": message}
+	if code != "" {
+		e["code"] = code
+	}
+	if param != "" {
+		e["param"] = param
+	}
+	return jsonResponse(status, map[string]interface{}{"error": e})
+}
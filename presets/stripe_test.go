@@ -0,0 +1,65 @@
+package presets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dankinder/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripe(t *testing.T) {
+	stripe := NewStripe()
+	stripe.APIKey = "sk_test_123"
+
+	s := httpmock.NewServer(stripe)
+	defer s.Close()
+
+	post := func(path string, form url.Values, idempotencyKey string) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest("POST", s.URL()+path, strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		req.SetBasicAuth("sk_test_123", "")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var obj map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&obj))
+		return resp, obj
+	}
+
+	resp, customer := post("/v1/customers", url.Values{"email": {"jenny@example.com"}}, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	form := url.Values{
+		"amount":         {"2000"},
+		"currency":       {"usd"},
+		"customer":       {customer["id"].(string)},
+		"payment_method": {"pm_card_visa"},
+		"confirm":        {"true"},
+	}
+	resp, pi := post("/v1/payment_intents", form, "key-1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "succeeded", pi["status"])
+
+	resp, replayed := post("/v1/payment_intents", form, "key-1")
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, pi["id"], replayed["id"])
+	assert.Len(t, stripe.PaymentIntents(), 1)
+
+	form.Set("payment_method", "pm_card_chargeDeclined")
+	resp, obj := post("/v1/payment_intents", form, "")
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+	assert.Equal(t, "card_declined", obj["error"].(map[string]interface{})["code"])
+
+	resp, obj = post("/v1/payment_intents", url.Values{"currency": {"usd"}}, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "amount", obj["error"].(map[string]interface{})["param"])
+}
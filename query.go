@@ -0,0 +1,53 @@
+package httpmock
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Query returns the parsed query string of the request, with every value of repeated parameters such as
+// "?id=1&id=2". Malformed pairs are skipped.
+func (r RequestInfo) Query() url.Values {
+	return ParseQuery(r.Path)
+}
+
+// ParseQuery returns the parsed query string of a request URI, such as the path argument of Handle, with every value
+// of repeated parameters. Malformed pairs are skipped.
+func ParseQuery(requestURI string) url.Values {
+	_, rawQuery, _ := strings.Cut(requestURI, "?")
+	query, _ := url.ParseQuery(rawQuery)
+	return query
+}
+
+// QueryMatcher returns a mock.MatchedBy func to check if the path argument is path with exactly the given query
+// parameters, in any order, but with the values of repeated parameters in the given order.
+//
+//	downstream.On("Handle", "GET", httpmock.QueryMatcher("/items", url.Values{"id": {"1", "2"}}), mock.Anything)
+func QueryMatcher(path string, query url.Values) interface{} {
+	return mock.MatchedBy(func(requestURI string) bool {
+		if stripQuery(requestURI) != path {
+			return false
+		}
+		actual := ParseQuery(requestURI)
+		if len(actual) == 0 && len(query) == 0 {
+			return true
+		}
+		return reflect.DeepEqual(actual, query)
+	})
+}
+
+// QueryParamMatcher returns a mock.MatchedBy func to check if the path argument has the query parameter key with
+// exactly the given values, in order, e.g. QueryParamMatcher("id", "1", "2") matches "/items?id=1&id=2&sort=asc".
+// Without values, it only checks that the parameter is present.
+func QueryParamMatcher(key string, values ...string) interface{} {
+	return mock.MatchedBy(func(requestURI string) bool {
+		actual, ok := ParseQuery(requestURI)[key]
+		if !ok {
+			return false
+		}
+		return len(values) == 0 || reflect.DeepEqual(actual, values)
+	})
+}
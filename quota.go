@@ -0,0 +1,139 @@
+package httpmock
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaConfig configures the Quota middleware.
+type QuotaConfig struct {
+	// The request header carrying the API key that usage is tracked by (default: "X-API-Key"). Requests without it
+	// share the budget of the empty key.
+	KeyHeader string
+	// The number of requests allowed per key
+	Limit int
+	// How often the budget of a key is restored in full, counting from its first request, or zero for a budget that is
+	// never restored. Ignored if RefillEvery is set.
+	Period time.Duration
+	// If set, the budget is a token bucket instead: it holds up to Limit requests and gets one more back every
+	// RefillEvery
+	RefillEvery time.Duration
+	// The status of requests once the budget is exhausted (default: 429 Too Many Requests). Use 402 Payment Required
+	// to simulate a billing quota.
+	Status int
+	// The clock used to restore budgets (default: SystemClock), see FakeClock
+	Clock Clock
+}
+
+// Quota returns a Middleware tracking usage per API key and answering requests with the configured status once a
+// key's budget is exhausted, without calling the handler, for testing that clients respect quotas and back off.
+// Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and, if the budget is ever restored,
+// X-RateLimit-Reset, the number of seconds until it is; rejected responses also carry Retry-After in that case.
+//
+//	clock := httpmock.NewFakeClock(time.Now())
+//	s := httpmock.NewServer(downstream, httpmock.WithMiddleware(httpmock.Quota(httpmock.QuotaConfig{
+//		Limit:  100,
+//		Period: time.Hour,
+//		Clock:  clock,
+//	})))
+func Quota(config QuotaConfig) Middleware {
+	if config.KeyHeader == "" {
+		config.KeyHeader = "X-API-Key"
+	}
+	if config.Status == 0 {
+		config.Status = http.StatusTooManyRequests
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	var mu sync.Mutex
+	var state middlewareState
+	budgets := make(map[string]*quotaBudget)
+
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		key := req.Header.Get(config.KeyHeader)
+		now := config.Clock.Now()
+		mu.Lock()
+		if state.expired(req) {
+			budgets = make(map[string]*quotaBudget)
+		}
+		b := budgets[key]
+		if b == nil {
+			b = &quotaBudget{since: now}
+			budgets[key] = b
+		}
+		b.restore(config, now)
+		allowed := b.used < config.Limit
+		if allowed {
+			b.used++
+		}
+		remaining, reset := config.Limit-b.used, b.reset(config, now)
+		mu.Unlock()
+
+		var resp Response
+		if allowed {
+			resp = next(req)
+			resp.Header = cloneHeader(resp.Header)
+		} else {
+			resp = Response{
+				Status: config.Status,
+				Header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+				Body:   []byte(fmt.Sprintf("quota of %d requests exceeded\n", config.Limit)),
+			}
+		}
+		resp.Header.Set("X-RateLimit-Limit", strconv.Itoa(config.Limit))
+		resp.Header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if reset >= 0 {
+			seconds := strconv.Itoa(int((reset + time.Second - 1) / time.Second))
+			resp.Header.Set("X-RateLimit-Reset", seconds)
+			if !allowed {
+				resp.Header.Set("Retry-After", seconds)
+			}
+		}
+		return resp
+	}
+}
+
+// quotaBudget is the usage of one API key, see Quota.
+type quotaBudget struct {
+	used int
+	// When the current period started, or when the token bucket was last refilled
+	since time.Time
+}
+
+// restore gives back the requests that are due at now.
+func (b *quotaBudget) restore(config QuotaConfig, now time.Time) {
+	switch {
+	case config.RefillEvery > 0:
+		refills := int(now.Sub(b.since) / config.RefillEvery)
+		if refills >= b.used {
+			b.used, b.since = 0, now
+		} else {
+			b.used -= refills
+			b.since = b.since.Add(time.Duration(refills) * config.RefillEvery)
+		}
+	case config.Period > 0:
+		if elapsed := now.Sub(b.since); elapsed >= config.Period {
+			b.used = 0
+			b.since = b.since.Add(elapsed / config.Period * config.Period)
+		}
+	}
+}
+
+// reset returns how long until some of the budget is given back, or -1 if it never is.
+func (b *quotaBudget) reset(config QuotaConfig, now time.Time) time.Duration {
+	switch {
+	case config.RefillEvery > 0:
+		if b.used == 0 {
+			return 0
+		}
+		return b.since.Add(config.RefillEvery).Sub(now)
+	case config.Period > 0:
+		return b.since.Add(config.Period).Sub(now)
+	default:
+		return -1
+	}
+}
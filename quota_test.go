@@ -0,0 +1,90 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuota(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	quota := Quota(QuotaConfig{Limit: 2, Period: time.Minute, Status: http.StatusPaymentRequired, Clock: clock})
+	next := func(req RequestInfo) Response { return Response{Body: []byte("ok")} }
+	request := func(key string) Response {
+		return quota(RequestInfo{Method: "GET", Path: "/", Header: http.Header{"X-Api-Key": {key}}}, next)
+	}
+
+	resp := request("a")
+	assert.Equal(t, "ok", string(resp.Body))
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "60", resp.Header.Get("X-RateLimit-Reset"))
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, "0", request("a").Header.Get("X-RateLimit-Remaining"))
+	resp = request("a")
+	assert.Equal(t, http.StatusPaymentRequired, resp.Status)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "50", resp.Header.Get("Retry-After"))
+
+	assert.Equal(t, 0, request("b").Status, "keys should have separate budgets")
+
+	clock.Advance(50 * time.Second)
+	resp = request("a")
+	assert.Equal(t, 0, resp.Status)
+	assert.Equal(t, "60", resp.Header.Get("X-RateLimit-Reset"))
+}
+
+func TestQuotaTokenBucket(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	quota := Quota(QuotaConfig{Limit: 2, RefillEvery: time.Second, Clock: clock})
+	next := func(req RequestInfo) Response { return Response{} }
+	request := func() Response { return quota(RequestInfo{Header: http.Header{}}, next) }
+
+	assert.Equal(t, 0, request().Status)
+	assert.Equal(t, 0, request().Status)
+	resp := request()
+	assert.Equal(t, http.StatusTooManyRequests, resp.Status)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	clock.Advance(1500 * time.Millisecond)
+	resp = request()
+	assert.Equal(t, 0, resp.Status)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, request().Status)
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, 0, request().Status)
+}
+
+func TestQuotaUnlimitedPeriod(t *testing.T) {
+	quota := Quota(QuotaConfig{Limit: 1})
+	next := func(req RequestInfo) Response { return Response{} }
+	assert.Empty(t, quota(RequestInfo{Header: http.Header{}}, next).Header.Get("X-RateLimit-Reset"))
+	resp := quota(RequestInfo{Header: http.Header{}}, next)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Status)
+	assert.Empty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestQuotaServerReset(t *testing.T) {
+	downstream := NewMockHandler(t)
+	s := NewServer(downstream, WithMiddleware(Quota(QuotaConfig{Limit: 1})))
+	defer s.Close()
+
+	get := func() int {
+		resp, err := http.Get(s.URL() + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{Status: 200})
+	assert.Equal(t, 200, get())
+	assert.Equal(t, http.StatusTooManyRequests, get())
+
+	s.Reset()
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(Response{Status: 200})
+	assert.Equal(t, 200, get(), "the budget should be restored by Reset")
+	assert.Equal(t, http.StatusTooManyRequests, get())
+}
@@ -0,0 +1,158 @@
+package httpmock
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// net/http canonicalizes header names as it parses them, so to recover their original case the server's connections
+// keep a copy of the raw header block of the request being read, which ServeHTTP picks up through the request context.
+
+type rawHeaderConnKey struct{}
+
+// rawHeaderListener wraps a listener so that its connections record raw request headers.
+type rawHeaderListener struct {
+	net.Listener
+}
+
+func (l rawHeaderListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &rawHeaderConn{Conn: c, recording: true}, nil
+}
+
+// rawHeaderConnContext makes the connection available to ServeHTTP, for use as http.Server.ConnContext. It is called
+// before the TLS handshake, if any.
+func rawHeaderConnContext(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(*tls.Conn); ok {
+		// The listener is wrapped by TLS, so the connection only sees encrypted bytes
+		if rc, ok := tc.NetConn().(*rawHeaderConn); ok {
+			rc.stopRecording()
+		}
+		return ctx
+	}
+	if rc, ok := c.(*rawHeaderConn); ok {
+		return context.WithValue(ctx, rawHeaderConnKey{}, rc)
+	}
+	return ctx
+}
+
+// rawHeaderConn records the bytes read from the connection until the end of a request's header block. Recording
+// resumes once the request's header names have been taken and its body has been read to the end, which happens before
+// the response is written, so the next request on the connection is recorded from its start.
+type rawHeaderConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	buf       []byte
+	recording bool
+}
+
+func (c *rawHeaderConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.mu.Lock()
+		if c.recording {
+			c.buf = append(c.buf, p[:n]...)
+			if i := bytes.Index(c.buf, []byte("\r\n\r\n")); i >= 0 {
+				c.buf = c.buf[:i+2]
+				c.recording = false
+			} else if len(c.buf) > http.DefaultMaxHeaderBytes {
+				// The server will reject the request anyway
+				c.buf = nil
+				c.recording = false
+			}
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+// takeHeaderNames returns the header names of the last request read, in the order received. It returns nil if no
+// complete header block was recorded. Recording stays off until resume.
+func (c *rawHeaderConn) takeHeaderNames() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	raw := c.buf
+	complete := !c.recording && raw != nil
+	c.buf = nil
+	c.recording = false
+	if !complete {
+		return nil
+	}
+
+	// Skip any empty lines preceding the request line, and the request line itself
+	lines := strings.Split(strings.TrimLeft(string(raw), "\r\n"), "\r\n")
+	names := []string{}
+	for _, line := range lines[1:] {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		if i := strings.IndexByte(line, ':'); i > 0 {
+			names = append(names, line[:i])
+		}
+	}
+	return names
+}
+
+// resume starts recording the next request, once the last one has been read in full.
+func (c *rawHeaderConn) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = nil
+	c.recording = true
+}
+
+// stopRecording stops recording for good, for connections whose reads can't be parsed as requests.
+func (c *rawHeaderConn) stopRecording() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = nil
+	c.recording = false
+}
+
+// rawHeaderNames returns the raw header names of r, if they were recorded. It must be called for every request before
+// its body is read and its response is written, so that the next request on the connection is recorded. Recording
+// resumes right away for requests without a body, and otherwise once r.Body is read to the end; if it never is, the
+// header names of the next request on the connection are not recorded.
+func rawHeaderNames(r *http.Request) []string {
+	if r.ProtoMajor != 1 {
+		return nil
+	}
+	c, ok := r.Context().Value(rawHeaderConnKey{}).(*rawHeaderConn)
+	if !ok {
+		return nil
+	}
+	names := c.takeHeaderNames()
+	if r.Body == nil || r.Body == http.NoBody {
+		c.resume()
+	} else {
+		r.Body = &resumingBody{ReadCloser: r.Body, conn: c}
+	}
+	return names
+}
+
+// resumingBody resumes recording on its connection once the request body has been read to the end, so that body bytes
+// are never taken for the next request's header block.
+type resumingBody struct {
+	io.ReadCloser
+	conn *rawHeaderConn
+	done bool
+}
+
+func (b *resumingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF && !b.done {
+		b.done = true
+		b.conn.resume()
+	}
+	return n, err
+}
@@ -0,0 +1,137 @@
+package httpmock
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawHeaderNames(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+	requests := &Captor[RequestInfo]{}
+	downstream.On("HandleRequest", requests.Matcher()).Return(Response{})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	for _, name := range []string{"x-api-key", "X-API-KEY"} {
+		req, err := http.NewRequest("POST", fmt.Sprintf("%s/keys", s.URL()), nil)
+		require.NoError(t, err)
+		// Assigning to the map directly bypasses canonicalization
+		req.Header[name] = []string{"secret"}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	values := requests.Values()
+	require.Len(t, values, 2)
+	assert.Contains(t, values[0].RawHeaderNames, "x-api-key")
+	assert.Contains(t, values[1].RawHeaderNames, "X-API-KEY")
+	assert.True(t, matches(HeaderMatcherExactCase("x-api-key", "secret"), values[0]))
+	assert.False(t, matches(HeaderMatcherExactCase("x-api-key", "secret"), values[1]))
+	assert.False(t, matches(HeaderMatcherExactCase("x-api-key", "other"), values[0]))
+
+	downstream.AssertExpectations(t)
+}
+
+func TestRawHeaderNamesAfterIgnoredRequest(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+	requests := &Captor[RequestInfo]{}
+	downstream.On("HandleRequest", requests.Matcher()).Return(Response{})
+
+	s := NewServer(downstream, IgnorePaths("/health"))
+	defer s.Close()
+
+	// Both requests go over the same keep-alive connection
+	client := &http.Client{Transport: &http.Transport{MaxConnsPerHost: 1}}
+	for _, r := range []struct{ path, header string }{{"/health", "x-health-probe"}, {"/real", "x-real"}} {
+		req, err := http.NewRequest("GET", s.URL()+r.path, nil)
+		require.NoError(t, err)
+		req.Header[r.header] = []string{"1"}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	require.Len(t, requests.Values(), 1)
+	assert.Contains(t, requests.Last().RawHeaderNames, "x-real")
+	assert.NotContains(t, requests.Last().RawHeaderNames, "x-health-probe")
+	downstream.AssertExpectations(t)
+}
+
+func TestRawHeaderNamesTLS(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+	requests := &Captor[RequestInfo]{}
+	downstream.On("HandleRequest", requests.Matcher()).Return(Response{})
+
+	s := NewUnstartedServer(downstream)
+	var mu sync.Mutex
+	var conns []*rawHeaderConn
+	s.httpServer.Config.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		ctx = s.connContext(ctx, c)
+		mu.Lock()
+		conns = append(conns, c.(*tls.Conn).NetConn().(*rawHeaderConn))
+		mu.Unlock()
+		return ctx
+	}
+	s.StartTLS()
+	defer s.Close()
+
+	resp, err := s.Client().Get(s.URL() + "/tls")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Nil(t, requests.Last().RawHeaderNames)
+	mu.Lock()
+	require.Len(t, conns, 1)
+	mu.Unlock()
+	conns[0].mu.Lock()
+	defer conns[0].mu.Unlock()
+	assert.False(t, conns[0].recording, "connections below TLS should not record")
+	assert.Nil(t, conns[0].buf, "the recorded handshake should be released")
+	downstream.AssertExpectations(t)
+}
+
+func TestRawHeaderNamesAfterBody(t *testing.T) {
+	downstream := NewMockHandlerWithRequest(t)
+	requests := &Captor[RequestInfo]{}
+	downstream.On("HandleRequest", requests.Matcher()).Return(Response{})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	// A large multipart body with blank lines past what is read along with the header block, followed by a second
+	// request on the same connection
+	part := func(name, content string) string {
+		return "--boundary\r\nContent-Disposition: form-data; name=\"" + name + "\"\r\n\r\n" + content + "\r\n"
+	}
+	body := part("file", strings.Repeat("x", 64<<10)) + part("name", "upload.txt") + "--boundary--\r\n"
+	client := &http.Client{Transport: &http.Transport{MaxConnsPerHost: 1}}
+	for _, r := range []struct {
+		method, path, header string
+		body                 io.Reader
+	}{{"POST", "/upload", "x-upload", strings.NewReader(body)}, {"GET", "/next", "x-next", nil}} {
+		req, err := http.NewRequest(r.method, s.URL()+r.path, r.body)
+		require.NoError(t, err)
+		req.Header[r.header] = []string{"1"}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	values := requests.Values()
+	require.Len(t, values, 2)
+	assert.Contains(t, values[0].RawHeaderNames, "x-upload")
+	assert.Contains(t, values[1].RawHeaderNames, "x-next")
+	assert.NotContains(t, values[1].RawHeaderNames, "Content-Disposition")
+	downstream.AssertExpectations(t)
+}
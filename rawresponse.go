@@ -0,0 +1,70 @@
+package httpmock
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// RawHandler takes over a connection whose request has been read, see Response.Hijack. rw buffers the connection, and
+// may already hold data the client sent after the request.
+type RawHandler func(conn net.Conn, rw *bufio.ReadWriter)
+
+// WithRawHandler makes the server hand the connection of every request for path (ignoring the query string) over to
+// fn, without calling the Handler, like a Response with Hijack set.
+func WithRawHandler(path string, fn RawHandler) Option {
+	return WithMiddleware(func(req RequestInfo, next func(RequestInfo) Response) Response {
+		if stripQuery(req.Path) == path {
+			return Response{Hijack: fn}
+		}
+		return next(req)
+	})
+}
+
+// hijack hands the connection over to fn, flushing rw and closing the connection once it returns. It returns an error
+// only if the connection couldn't be taken over, since the response can't be replaced afterwards.
+func hijack(w http.ResponseWriter, fn RawHandler) error {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return fmt.Errorf("connection can't be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	fn(conn, rw)
+	// Failing to flush means the client has gone away, e.g. after reading as much as it wanted
+	_ = rw.Flush()
+	return nil
+}
+
+// writeRawResponse writes a response with a custom status line by hijacking the connection, since http.ResponseWriter
+// always uses the standard reason phrase. The connection is closed afterwards.
+func writeRawResponse(w http.ResponseWriter, r *http.Request, status int, reason string, body []byte) error {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return fmt.Errorf("%s connections can't be hijacked", r.Proto)
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	header := cloneHeader(w.Header())
+	header.Set("Connection", "close")
+	if r.Method == http.MethodHead {
+		body = nil
+	} else {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	fmt.Fprintf(buf, "HTTP/1.1 %03d %s\r\n", status, reason)
+	_ = header.Write(buf)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Flush()
+}
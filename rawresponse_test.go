@@ -0,0 +1,79 @@
+package httpmock
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseReason(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/custom", mock.Anything).Return(Response{
+		Status: 299,
+		Reason: "Custom",
+		Header: http.Header{"X-Test": {"yes"}},
+		Body:   []byte("hello"),
+	})
+
+	s := NewServer(downstream)
+	defer s.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%s/custom", s.URL()))
+	require.NoError(t, err)
+	assert.Equal(t, "299 Custom", resp.Status)
+	assert.Equal(t, 299, resp.StatusCode)
+	assert.Equal(t, "yes", resp.Header.Get("X-Test"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	downstream.AssertExpectations(t)
+}
+
+func TestRawHandler(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/normal", mock.Anything).Return(Response{Body: []byte("normal")})
+
+	// An echo protocol, upgraded to from HTTP
+	echo := func(conn net.Conn, rw *bufio.ReadWriter) {
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		rw.WriteString("echo: " + line)
+	}
+	s := NewServer(downstream, WithRawHandler("/echo", echo))
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(s.URL(), "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /echo?x=1 HTTP/1.1\r\nHost: test\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	fmt.Fprintf(conn, "hello\n")
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "echo: hello\n", line)
+
+	resp, err = http.Get(s.URL() + "/normal")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "normal", string(body))
+
+	downstream.AssertExpectations(t)
+}
@@ -0,0 +1,75 @@
+package httpmock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Report summarizes how the expectations of a mock handler were used, for diagnosing a failing test. Its String
+// method pretty-prints it:
+//
+//	if !downstream.AssertExpectations(t) {
+//		t.Log(s.Report())
+//	}
+type Report struct {
+	// The expectations, in the order they were registered
+	Expectations []ExpectationUsage
+	// The first requests that matched no expectation, in the order they were received. Only the first 100 are kept, so
+	// that long-running servers don't accumulate them.
+	Unmatched []RequestInfo
+	// The number of requests that matched no expectation, including those not kept in Unmatched
+	UnmatchedCount int
+}
+
+// ExpectationUsage is the usage of an expectation, in a Report.
+type ExpectationUsage struct {
+	// The mocked method and the expected arguments, as given to On
+	Method    string
+	Arguments mock.Arguments
+	// The number of requests that matched the expectation
+	Matched int
+	// When the first and last matching requests were handled, zero if none were
+	FirstMatch, LastMatch time.Time
+	// The first matching request, or nil if none matched
+	Sample *RequestInfo
+}
+
+// Report summarizes how the expectations of the server's handler were used, if it is one of the mock handlers of this
+// package. For other handlers, the report is empty.
+func (s *Server) Report() Report {
+	if r, ok := s.handler.(interface{ Report() Report }); ok {
+		return r.Report()
+	}
+	return Report{}
+}
+
+// String pretty-prints the report.
+func (r Report) String() string {
+	var b strings.Builder
+	b.WriteString("Expectations:\n")
+	if len(r.Expectations) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, e := range r.Expectations {
+		fmt.Fprintf(&b, "  %s(%s): ", e.Method, formatArgs(e.Arguments))
+		if e.Matched == 0 {
+			b.WriteString("never matched\n")
+			continue
+		}
+		fmt.Fprintf(&b, "matched %d times, first at %s, last at %s, e.g. %s %s\n", e.Matched,
+			e.FirstMatch.Format("15:04:05.000"), e.LastMatch.Format("15:04:05.000"), e.Sample.Method, e.Sample.Path)
+	}
+	if len(r.Unmatched) > 0 {
+		b.WriteString("Unmatched requests:\n")
+		for _, req := range r.Unmatched {
+			fmt.Fprintf(&b, "  %s %s\n", req.Method, req.Path)
+		}
+		if more := r.UnmatchedCount - len(r.Unmatched); more > 0 {
+			fmt.Fprintf(&b, "  and %d more\n", more)
+		}
+	}
+	return b.String()
+}
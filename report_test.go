@@ -0,0 +1,60 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/a", mock.Anything).Return(Response{})
+	downstream.On("Handle", "GET", "/b", mock.Anything).Return(Response{})
+	s := NewServer(downstream, OnPanic(func(RequestInfo, interface{}, []byte) {}))
+	defer s.Close()
+
+	for _, path := range []string{"/a", "/a", "/c"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	report := s.Report()
+	require.Len(t, report.Expectations, 2)
+	a := report.Expectations[0]
+	assert.Equal(t, "Handle", a.Method)
+	assert.Equal(t, mock.Arguments{"GET", "/a", mock.Anything}, a.Arguments)
+	assert.Equal(t, 2, a.Matched)
+	assert.False(t, a.FirstMatch.IsZero())
+	assert.False(t, a.LastMatch.Before(a.FirstMatch))
+	assert.Equal(t, "/a", a.Sample.Path)
+	assert.Equal(t, 0, report.Expectations[1].Matched)
+	assert.Nil(t, report.Expectations[1].Sample)
+	require.Len(t, report.Unmatched, 1)
+	assert.Equal(t, "/c", report.Unmatched[0].Path)
+	assert.Equal(t, 1, report.UnmatchedCount)
+
+	str := report.String()
+	assert.Contains(t, str, "  Handle(GET, /a, mock.Anything): matched 2 times, first at ")
+	assert.Contains(t, str, "  Handle(GET, /b, mock.Anything): never matched\n")
+	assert.Contains(t, str, "Unmatched requests:\n  GET /c\n")
+
+	s.Reset()
+	assert.Equal(t, Report{}, s.Report())
+	assert.Equal(t, Report{}, NewUnstartedServer(&OKHandler{}).Report())
+}
+
+func TestReportUnmatchedLimit(t *testing.T) {
+	downstream := &MockHandler{}
+	for i := 0; i < maxUnmatched+5; i++ {
+		assert.Panics(t, func() { downstream.Handle("GET", "/unexpected", []byte("body")) })
+	}
+
+	report := downstream.Report()
+	assert.Len(t, report.Unmatched, maxUnmatched)
+	assert.Equal(t, maxUnmatched+5, report.UnmatchedCount)
+	assert.Contains(t, report.String(), "  GET /unexpected\n  and 5 more\n")
+}
@@ -0,0 +1,117 @@
+package httpmock
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ToXMLResponse returns a Response with the given status whose body is obj marshaled to XML, preceded by the standard
+// XML declaration, and with Content-Type application/xml. Like ToXML, it panics if obj can't be marshaled. To leave
+// out the declaration, use Response{Body: ToXML(obj)} instead.
+func ToXMLResponse(status int, obj interface{}) Response {
+	return Response{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/xml; charset=utf-8"}},
+		Body:   append([]byte(xml.Header), ToXML(obj)...),
+	}
+}
+
+// AttachmentResponse returns a Response serving data as a file download named filename. Content-Disposition and
+// Content-Length are set accordingly, and Content-Type is derived from the file extension, or sniffed from data if the
+// extension is unknown. To specify the Content-Type instead, set it on the returned Response's Header.
+func AttachmentResponse(filename string, data []byte) Response {
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return Response{
+		Header: http.Header{
+			"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+			"Content-Type":        {contentType},
+			"Content-Length":      {strconv.Itoa(len(data))},
+		},
+		Body: data,
+	}
+}
+
+// Cacheable returns headers marking a response as cacheable by any cache for maxAge, to be used as (or merged into) a
+// Response's Header.
+//
+//	Return(httpmock.Response{Header: httpmock.Cacheable(time.Minute), Body: body})
+func Cacheable(maxAge time.Duration) http.Header {
+	return http.Header{"Cache-Control": {fmt.Sprintf("public, max-age=%d", seconds(maxAge))}}
+}
+
+// NoCache returns headers allowing a response to be stored, but requiring caches to revalidate it before every reuse.
+func NoCache() http.Header {
+	return http.Header{"Cache-Control": {"no-cache"}}
+}
+
+// NoStore returns headers forbidding any cache from storing a response. Pragma is included for HTTP/1.0 caches.
+func NoStore() http.Header {
+	return http.Header{
+		"Cache-Control": {"no-store"},
+		"Pragma":        {"no-cache"},
+	}
+}
+
+// StaleWhileRevalidate returns headers marking a response as fresh for maxAge, after which caches may keep serving it
+// for up to stale while revalidating it in the background (RFC 5861).
+func StaleWhileRevalidate(maxAge, stale time.Duration) http.Header {
+	return http.Header{
+		"Cache-Control": {fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", seconds(maxAge), seconds(stale))},
+	}
+}
+
+// ConflictingCacheHeaders returns headers that disagree on how long a response is fresh: Cache-Control allows maxAge,
+// while an Expires date in the past and Pragma: no-cache say it is stale. Per RFC 9111, max-age takes precedence over
+// Expires and Cache-Control over Pragma, so caches should treat the response as fresh for maxAge.
+func ConflictingCacheHeaders(maxAge time.Duration) http.Header {
+	return http.Header{
+		"Cache-Control": {fmt.Sprintf("public, max-age=%d", seconds(maxAge))},
+		"Expires":       {"Thu, 01 Jan 1970 00:00:00 GMT"},
+		"Pragma":        {"no-cache"},
+	}
+}
+
+// ContradictoryCacheControl returns headers with two Cache-Control lines, one allowing a response to be cached for
+// maxAge and one forbidding it from being stored. Recipients must combine repeated Cache-Control headers, and
+// no-store then wins, so caches shouldn't store the response.
+func ContradictoryCacheControl(maxAge time.Duration) http.Header {
+	return http.Header{"Cache-Control": {fmt.Sprintf("public, max-age=%d", seconds(maxAge)), "no-store"}}
+}
+
+// VaryResponse returns a Response that is computed when it is served, picking the variant for the value of the request
+// header name (the "" variant for requests without it), or fallback if there is none, and adding name to its Vary
+// header. Serving different bodies with the right Vary validates that HTTP caches in clients key on the header.
+//
+//	downstream.On("HandleWithHeaders", "GET", "/greeting", mock.Anything, mock.Anything).Return(httpmock.VaryResponse(
+//		"Accept-Language",
+//		map[string]httpmock.Response{
+//			"fr": {Header: httpmock.Cacheable(time.Minute), Body: []byte("bonjour")},
+//		},
+//		httpmock.Response{Header: httpmock.Cacheable(time.Minute), Body: []byte("hello")}))
+func VaryResponse(name string, variants map[string]Response, fallback Response) Response {
+	return Response{compute: func(req RequestInfo) Response {
+		resp, ok := variants[req.Header.Get(name)]
+		if !ok {
+			resp = fallback
+		}
+		if resp.compute != nil {
+			resp = resp.compute(req)
+		}
+		resp.Header = cloneHeader(resp.Header)
+		resp.Header.Add("Vary", http.CanonicalHeaderKey(name))
+		return resp
+	}}
+}
+
+// seconds converts d to whole seconds, as used by caching headers.
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
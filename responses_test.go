@@ -0,0 +1,186 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToXMLResponse(t *testing.T) {
+	type envelope struct {
+		XMLName xml.Name `xml:"Envelope"`
+		Body    string   `xml:"Body"`
+	}
+
+	resp := ToXMLResponse(500, envelope{Body: "fault"})
+	assert.Equal(t, 500, resp.Status)
+	assert.Equal(t, "application/xml; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, xml.Header+"<Envelope><Body>fault</Body></Envelope>", string(resp.Body))
+}
+
+func TestByteRanges(t *testing.T) {
+	content := []byte("0123456789")
+	resp := NewByteRanges().
+		SetBoundary("THIS_STRING_SEPARATES").
+		AddRange("text/plain", content, 0, 2).
+		AddRange("text/plain", content, 7, 9).
+		Response()
+
+	assert.Equal(t, 206, resp.Status)
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	r := multipart.NewReader(bytes.NewReader(resp.Body), params["boundary"])
+	var ranges, bodies []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		ranges = append(ranges, part.Header.Get("Content-Range"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"bytes 0-2/10", "bytes 7-9/10"}, ranges)
+	assert.Equal(t, []string{"012", "789"}, bodies)
+}
+
+func TestAttachmentResponse(t *testing.T) {
+	resp := AttachmentResponse("report 1.json", []byte(`{"a":1}`))
+	assert.Equal(t, `attachment; filename="report 1.json"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "7", resp.Header.Get("Content-Length"))
+
+	resp = AttachmentResponse("blob", []byte("%PDF-1.4"))
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+}
+
+func TestCachingHeaders(t *testing.T) {
+	assert.Equal(t, "public, max-age=60", Cacheable(time.Minute).Get("Cache-Control"))
+	assert.Equal(t, "no-cache", NoCache().Get("Cache-Control"))
+	assert.Equal(t, "no-store", NoStore().Get("Cache-Control"))
+	assert.Equal(t, "public, max-age=10, stale-while-revalidate=30",
+		StaleWhileRevalidate(10*time.Second, 30*time.Second).Get("Cache-Control"))
+	assert.Equal(t, "public, max-age=60", ConflictingCacheHeaders(time.Minute).Get("Cache-Control"))
+	assert.Equal(t, "no-cache", ConflictingCacheHeaders(time.Minute).Get("Pragma"))
+	assert.Equal(t, []string{"public, max-age=60", "no-store"}, ContradictoryCacheControl(time.Minute)["Cache-Control"])
+}
+
+func TestGRPCWeb(t *testing.T) {
+	resp := GRPCWebResponse([][]byte{[]byte("first"), []byte("second")}, 5, "not found: 100%")
+	assert.Equal(t, "application/grpc-web+proto", resp.Header.Get("Content-Type"))
+
+	messages, trailers, err := GRPCWebFrames(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("first"), []byte("second")}, messages)
+	assert.Equal(t, "5", trailers.Get("grpc-status"))
+	assert.Equal(t, "not found: 100%25", trailers.Get("grpc-message"))
+
+	request := []byte{0, 0, 0, 0, 3, 'a', 'b', 'c'}
+	assert.True(t, matches(GRPCWebMessageMatcher([]byte("abc")), request))
+	assert.False(t, matches(GRPCWebMessageMatcher([]byte("abd")), request))
+	assert.False(t, matches(GRPCWebMessageMatcher([]byte("abc")), request[:6]))
+}
+
+func TestGRPCWebFramesTrailerNotLast(t *testing.T) {
+	var buf bytes.Buffer
+	writeGRPCWebFrame(&buf, grpcWebTrailerFlag, []byte("grpc-status: 0\r\n"))
+	writeGRPCWebFrame(&buf, 0, []byte("message"))
+	body := buf.Bytes()
+	original := append([]byte(nil), body...)
+
+	messages, trailers, err := GRPCWebFrames(body)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("message")}, messages)
+	assert.Equal(t, "0", trailers.Get("grpc-status"))
+	assert.Equal(t, original, body, "the body must not be modified")
+}
+
+func TestXMLRPC(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	call := []byte(`<?xml version="1.0"?>
+<methodCall>
+  <methodName>geo.distance</methodName>
+  <params>
+    <param><value><struct>
+      <member><name>X</name><value><i4>1</i4></value></member>
+      <member><name>Y</name><value><int>2</int></value></member>
+    </struct></value></param>
+    <param><value>untyped</value></param>
+    <param><value><array><data><value><boolean>1</boolean></value><value><double>1.5</double></value></data></array></value></param>
+  </params>
+</methodCall>`)
+
+	assert.True(t, matches(XMLRPCMethodMatcher("geo.distance", point{1, 2}, "untyped", []interface{}{true, 1.5}), call))
+	assert.False(t, matches(XMLRPCMethodMatcher("geo.distance", point{1, 3}, "untyped", []interface{}{true, 1.5}), call))
+	assert.False(t, matches(XMLRPCMethodMatcher("geo.area", point{1, 2}, "untyped", []interface{}{true, 1.5}), call))
+
+	resp := XMLRPCResponse(map[string]interface{}{"distance": 2.5})
+	assert.Equal(t, "text/xml", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(resp.Body),
+		"<params><param><value><struct><member><name>distance</name><value><double>2.5</double></value></member></struct></value></param></params>")
+
+	resp = XMLRPCFault(4, "Too many <params>")
+	assert.Contains(t, string(resp.Body), "<fault><value><struct>")
+	assert.Contains(t, string(resp.Body), "<string>Too many &lt;params&gt;</string>")
+}
+
+func TestTextResponse(t *testing.T) {
+	resp := TextResponse(200, "café ☕", "ISO-8859-1")
+	assert.Equal(t, "text/plain; charset=iso-8859-1", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []byte("caf\xe9 ?"), resp.Body)
+
+	assert.Equal(t, []byte{0xfe, 0xff, 0, 'h', 0, 0xe9}, EncodeText("hé", "utf-16"))
+	assert.Equal(t, []byte{'h', 0, 0xe9, 0}, EncodeText("hé", "UTF-16LE"))
+	assert.Equal(t, []byte("h?"), EncodeText("hé", "us-ascii"))
+	assert.Equal(t, "text/html; charset=utf-16", TextContentType("text/html", "UTF-16"))
+	assert.Panics(t, func() { EncodeText("x", "ebcdic") })
+}
+
+func TestVaryResponse(t *testing.T) {
+	resp := VaryResponse("accept-language", map[string]Response{
+		"fr": {Header: Cacheable(time.Minute), Body: []byte("bonjour")},
+		"":   {Status: 400},
+	}, Response{Header: http.Header{"Vary": {"Accept-Encoding"}}, Body: []byte("hello")})
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/greeting", mock.Anything).Return(resp)
+	s := NewServer(downstream)
+	defer s.Close()
+
+	get := func(language string) (*http.Response, string) {
+		req, err := http.NewRequest("GET", s.URL()+"/greeting", nil)
+		require.NoError(t, err)
+		if language != "" {
+			req.Header.Set("Accept-Language", language)
+		}
+		r, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer r.Body.Close()
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		return r, string(body)
+	}
+	r, body := get("fr")
+	assert.Equal(t, "bonjour", body)
+	assert.Equal(t, []string{"Accept-Language"}, r.Header["Vary"])
+	assert.Equal(t, "public, max-age=60", r.Header.Get("Cache-Control"))
+	r, body = get("de")
+	assert.Equal(t, "hello", body)
+	assert.Equal(t, []string{"Accept-Encoding", "Accept-Language"}, r.Header["Vary"])
+	r, _ = get("")
+	assert.Equal(t, 400, r.StatusCode)
+}
@@ -0,0 +1,123 @@
+package httpmock
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResumableUploadHandler is a HandlerWithHeaders implementing a minimal version of the tus resumable upload protocol
+// (https://tus.io): uploads are created with a POST to the base path carrying an Upload-Length header, their offset is
+// queried with HEAD, and data is appended with PATCH requests carrying the current Upload-Offset. It lets chunked and
+// resumable upload clients be tested against httpmock.
+type ResumableUploadHandler struct {
+	// MaxChunkSize limits how many bytes of a single PATCH are accepted (default: no limit). The rest is discarded,
+	// as if the connection had dropped, so the client has to query the offset and resume.
+	MaxChunkSize int
+
+	basePath string
+	mu       sync.Mutex
+	uploads  map[string]*resumableUpload
+	nextID   int
+}
+
+type resumableUpload struct {
+	length int
+	data   []byte
+}
+
+// NewResumableUploadHandler returns a ResumableUploadHandler serving uploads under basePath, e.g. "/files/".
+func NewResumableUploadHandler(basePath string) *ResumableUploadHandler {
+	return &ResumableUploadHandler{
+		basePath: strings.TrimSuffix(basePath, "/") + "/",
+		uploads:  make(map[string]*resumableUpload),
+	}
+}
+
+// Reset forgets all uploads, see Server.Reset.
+func (h *ResumableUploadHandler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.uploads = make(map[string]*resumableUpload)
+	h.nextID = 0
+}
+
+// Upload returns the data received so far for the upload with the given ID (the last segment of its Location), and
+// whether the upload is complete.
+func (h *ResumableUploadHandler) Upload(id string) (data []byte, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	u, ok := h.uploads[id]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), u.data...), len(u.data) == u.length
+}
+
+// Handle makes this implement the Handler interface.
+func (h *ResumableUploadHandler) Handle(method, path string, body []byte) Response {
+	return h.HandleWithHeaders(method, path, http.Header{}, body)
+}
+
+// HandleWithHeaders makes this implement the HandlerWithHeaders interface.
+func (h *ResumableUploadHandler) HandleWithHeaders(method, path string, headers http.Header, body []byte) Response {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path = stripQuery(path)
+	if strings.TrimSuffix(path, "/")+"/" == h.basePath {
+		if method != http.MethodPost {
+			return tusResponse(http.StatusMethodNotAllowed, nil)
+		}
+		length, err := strconv.Atoi(headers.Get("Upload-Length"))
+		if err != nil || length < 0 {
+			return tusResponse(http.StatusBadRequest, nil)
+		}
+		h.nextID++
+		id := strconv.Itoa(h.nextID)
+		h.uploads[id] = &resumableUpload{length: length}
+		return tusResponse(http.StatusCreated, http.Header{"Location": {h.basePath + id}})
+	}
+
+	u, ok := h.uploads[strings.TrimPrefix(path, h.basePath)]
+	if !strings.HasPrefix(path, h.basePath) || !ok {
+		return tusResponse(http.StatusNotFound, nil)
+	}
+
+	switch method {
+	case http.MethodHead:
+		return tusResponse(http.StatusOK, http.Header{
+			"Upload-Offset": {strconv.Itoa(len(u.data))},
+			"Upload-Length": {strconv.Itoa(u.length)},
+			"Cache-Control": {"no-store"},
+		})
+	case http.MethodPatch:
+		if headers.Get("Content-Type") != "application/offset+octet-stream" {
+			return tusResponse(http.StatusUnsupportedMediaType, nil)
+		}
+		offset, err := strconv.Atoi(headers.Get("Upload-Offset"))
+		if err != nil || offset != len(u.data) {
+			return tusResponse(http.StatusConflict, nil)
+		}
+		if len(u.data)+len(body) > u.length {
+			return tusResponse(http.StatusRequestEntityTooLarge, nil)
+		}
+		if h.MaxChunkSize > 0 && len(body) > h.MaxChunkSize {
+			body = body[:h.MaxChunkSize]
+		}
+		u.data = append(u.data, body...)
+		return tusResponse(http.StatusNoContent, http.Header{"Upload-Offset": {strconv.Itoa(len(u.data))}})
+	}
+	return tusResponse(http.StatusMethodNotAllowed, nil)
+}
+
+// tusResponse returns a Response with the given status and headers, plus the Tus-Resumable header every tus response
+// carries.
+func tusResponse(status int, header http.Header) Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Tus-Resumable", "1.0.0")
+	return Response{Status: status, Header: header}
+}
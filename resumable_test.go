@@ -0,0 +1,61 @@
+package httpmock
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumableUploadHandler(t *testing.T) {
+	handler := NewResumableUploadHandler("/files/")
+	handler.MaxChunkSize = 4
+	s := NewServer(handler)
+	defer s.Close()
+
+	do := func(method, url string, header http.Header, body string) *http.Response {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", resp.Header.Get("Tus-Resumable"))
+		return resp
+	}
+
+	resp := do("POST", s.URL()+"/files", http.Header{"Upload-Length": {"10"}}, "")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	location := s.URL() + resp.Header.Get("Location")
+
+	// Only the first 4 bytes are accepted, so the client has to resume from there
+	resp = do("PATCH", location, http.Header{
+		"Content-Type":  {"application/offset+octet-stream"},
+		"Upload-Offset": {"0"},
+	}, "0123456789")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "4", resp.Header.Get("Upload-Offset"))
+
+	resp = do("PATCH", location, http.Header{
+		"Content-Type":  {"application/offset+octet-stream"},
+		"Upload-Offset": {"0"},
+	}, "0123")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp = do("HEAD", location, nil, "")
+	assert.Equal(t, "4", resp.Header.Get("Upload-Offset"))
+	assert.Equal(t, "10", resp.Header.Get("Upload-Length"))
+
+	for _, chunk := range []string{"4567", "89"} {
+		resp = do("PATCH", location, http.Header{
+			"Content-Type":  {"application/offset+octet-stream"},
+			"Upload-Offset": {resp.Header.Get("Upload-Offset")},
+		}, chunk)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	data, complete := handler.Upload("1")
+	assert.True(t, complete)
+	assert.Equal(t, "0123456789", string(data))
+}
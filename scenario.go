@@ -0,0 +1,129 @@
+package httpmock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Scenario manages a set of named mock servers that together make up a fake environment, e.g. "auth", "catalog" and
+// "payments" downstreams, for integration tests. Servers are added unstarted, then started and closed together.
+//
+//	sc := httpmock.NewScenario()
+//	sc.Add("auth", auth)
+//	sc.Add("catalog", catalog)
+//	sc.Start()
+//	defer sc.Close()
+//
+//	app := NewApp(sc.URLs()["auth"], sc.URLs()["catalog"])
+//	// ...
+//	sc.AssertExpectations(t)
+type Scenario struct {
+	names    []string
+	servers  map[string]*Server
+	handlers map[string]Handler
+	store    *Store
+}
+
+// NewScenario returns an empty Scenario.
+func NewScenario() *Scenario {
+	return &Scenario{
+		servers:  make(map[string]*Server),
+		handlers: make(map[string]Handler),
+		store:    NewStore(),
+	}
+}
+
+// Add constructs an unstarted server for handler under the given name and returns it. It panics if the name is
+// already taken.
+func (sc *Scenario) Add(name string, handler Handler, opts ...Option) *Server {
+	if _, ok := sc.servers[name]; ok {
+		panic(fmt.Sprintf("httpmock: scenario already has a server named %q", name))
+	}
+	s := NewUnstartedServer(handler, opts...)
+	sc.names = append(sc.names, name)
+	sc.servers[name] = s
+	sc.handlers[name] = handler
+	return s
+}
+
+// Server returns the server with the given name, or nil if there is none.
+func (sc *Scenario) Server(name string) *Server {
+	return sc.servers[name]
+}
+
+// Store returns the Store shared by the servers of the scenario, see Store.
+func (sc *Scenario) Store() *Store {
+	return sc.store
+}
+
+// Start starts all servers, in the order they were added.
+func (sc *Scenario) Start() {
+	for _, name := range sc.names {
+		sc.servers[name].Start()
+	}
+}
+
+// Close shuts down all servers, in the reverse order they were added.
+func (sc *Scenario) Close() {
+	for i := len(sc.names) - 1; i >= 0; i-- {
+		sc.servers[sc.names[i]].Close()
+	}
+}
+
+// URLs returns the URL of every started server, keyed by name.
+func (sc *Scenario) URLs() map[string]string {
+	urls := make(map[string]string, len(sc.servers))
+	for name, s := range sc.servers {
+		urls[name] = s.URL()
+	}
+	return urls
+}
+
+// AssertExpectations asserts the expectations of every handler that supports it (such as MockHandler), reporting
+// failures to t. It returns whether all of them passed.
+func (sc *Scenario) AssertExpectations(t mock.TestingT) bool {
+	ok := true
+	for _, name := range sc.names {
+		if m, isMock := sc.handlers[name].(interface{ AssertExpectations(mock.TestingT) bool }); isMock {
+			if !m.AssertExpectations(t) {
+				t.Errorf("httpmock: expectations of scenario server %q were not met", name)
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// EnvVar returns the environment variable Setenv and Env use for the server with the given name: the name upper-cased,
+// with characters other than letters and digits replaced by underscores, followed by "_URL". For example, the server
+// "user-profiles" is exported as USER_PROFILES_URL.
+func EnvVar(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name) + "_URL"
+}
+
+// Setenv exports the URL of every server into the environment of the test process, under the names given by EnvVar.
+// They are restored when the test finishes.
+func (sc *Scenario) Setenv(t testing.TB) {
+	for _, name := range sc.names {
+		sc.servers[name].Setenv(t, EnvVar(name))
+	}
+}
+
+// Env returns the URL of every server as "KEY=value" strings, under the names given by EnvVar, for use in the Env of
+// an exec.Cmd that launches the binary under test.
+func (sc *Scenario) Env() []string {
+	env := make([]string, 0, len(sc.names))
+	for _, name := range sc.names {
+		env = append(env, sc.servers[name].Env(EnvVar(name)))
+	}
+	return env
+}
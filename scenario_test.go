@@ -0,0 +1,55 @@
+package httpmock
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario(t *testing.T) {
+	auth := NewMockHandler(t)
+	auth.On("Handle", "POST", "/token", mock.Anything).Return(Response{Body: []byte("token")})
+	catalog := NewMockHandler(t)
+	catalog.On("Handle", "GET", "/items", mock.Anything).Return(Response{Body: []byte("[]")})
+
+	sc := NewScenario()
+	sc.Add("auth", auth)
+	sc.Add("catalog", catalog)
+	assert.Panics(t, func() { sc.Add("auth", &OKHandler{}) })
+	sc.Start()
+	defer sc.Close()
+
+	urls := sc.URLs()
+	require.Len(t, urls, 2)
+	assert.Equal(t, sc.Server("auth").URL(), urls["auth"])
+	assert.NotEqual(t, urls["auth"], urls["catalog"])
+
+	_, err := http.Post(urls["auth"]+"/token", "text/plain", nil)
+	require.NoError(t, err)
+	_, err = http.Get(urls["catalog"] + "/items")
+	require.NoError(t, err)
+
+	assert.True(t, sc.AssertExpectations(t))
+}
+
+func TestScenarioEnv(t *testing.T) {
+	sc := NewScenario()
+	sc.Add("auth", &OKHandler{})
+	sc.Add("user-profiles", &OKHandler{})
+	sc.Start()
+	defer sc.Close()
+
+	assert.Equal(t, "USER_PROFILES_URL", EnvVar("user-profiles"))
+	assert.Equal(t, []string{
+		"AUTH_URL=" + sc.Server("auth").URL(),
+		"USER_PROFILES_URL=" + sc.Server("user-profiles").URL(),
+	}, sc.Env())
+
+	sc.Setenv(t)
+	assert.Equal(t, sc.Server("auth").URL(), os.Getenv("AUTH_URL"))
+	assert.Equal(t, sc.Server("user-profiles").URL(), os.Getenv("USER_PROFILES_URL"))
+}
@@ -0,0 +1,110 @@
+package httpmock
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// SessionHandler is a HandlerWithRequest simulating sticky sessions: requests that don't present a session cookie it
+// issued are served by NoSession, and get a new session cookie in their response, while requests presenting one are
+// served by Session. It lets clients' cookie jars be tested, e.g. that they send the cookie back, only to the right
+// domain and path, and drop it once it expires.
+//
+//	first, returning := &httpmock.MockHandler{}, &httpmock.MockHandler{}
+//	first.On("Handle", "GET", "/", mock.Anything).Return(httpmock.Response{Body: []byte("welcome")})
+//	returning.On("Handle", "GET", "/", mock.Anything).Return(httpmock.Response{Body: []byte("welcome back")})
+//	s := httpmock.NewServer(httpmock.NewSessionHandler(first, returning))
+type SessionHandler struct {
+	// The template of the issued cookies, whose Value is set to the session ID (default: a cookie named "session" with
+	// Path "/" and HttpOnly). Set e.g. Domain, Secure or MaxAge to test how clients honor them.
+	Cookie *http.Cookie
+	// Serves requests with and without a valid session cookie
+	Session, NoSession Handler
+
+	mu       sync.Mutex
+	sessions map[string]int
+	ids      []string
+	without  int
+	// Not reset, so that session IDs are never reused
+	issued int
+}
+
+// NewSessionHandler returns a SessionHandler serving requests without a session with noSession, and requests with one
+// with session.
+func NewSessionHandler(noSession, session Handler) *SessionHandler {
+	return &SessionHandler{NoSession: noSession, Session: session}
+}
+
+// Sessions returns the IDs of the sessions issued so far, in order.
+func (h *SessionHandler) Sessions() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.ids...)
+}
+
+// SessionRequests returns the number of requests that presented the cookie of the session with the given ID.
+func (h *SessionHandler) SessionRequests(id string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sessions[id]
+}
+
+// RequestsWithoutSession returns the number of requests that presented no valid session cookie, including ones with
+// the cookie of an unknown session.
+func (h *SessionHandler) RequestsWithoutSession() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.without
+}
+
+// Reset forgets all sessions, see Server.Reset. Clients presenting their cookies then get new ones.
+func (h *SessionHandler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions = nil
+	h.ids = nil
+	h.without = 0
+}
+
+// Handle makes this implement the Handler interface.
+func (h *SessionHandler) Handle(method, path string, body []byte) Response {
+	return h.HandleRequest(RequestInfo{Method: method, Path: path, Header: http.Header{}, Body: body})
+}
+
+// HandleRequest makes this implement the HandlerWithRequest interface.
+func (h *SessionHandler) HandleRequest(req RequestInfo) Response {
+	template := h.Cookie
+	if template == nil {
+		template = &http.Cookie{Name: "session", Path: "/", HttpOnly: true}
+	}
+	if c, err := (&http.Request{Header: req.Header}).Cookie(template.Name); err == nil {
+		h.mu.Lock()
+		_, ok := h.sessions[c.Value]
+		if ok {
+			h.sessions[c.Value]++
+		}
+		h.mu.Unlock()
+		if ok {
+			return ServeRequest(h.Session, req)
+		}
+	}
+
+	h.mu.Lock()
+	if h.sessions == nil {
+		h.sessions = make(map[string]int)
+	}
+	h.issued++
+	id := "session-" + strconv.Itoa(h.issued)
+	h.sessions[id] = 0
+	h.ids = append(h.ids, id)
+	h.without++
+	h.mu.Unlock()
+
+	resp := ServeRequest(h.NoSession, req)
+	resp.Header = cloneHeader(resp.Header)
+	cookie := *template
+	cookie.Value = id
+	resp.Header.Add("Set-Cookie", cookie.String())
+	return resp
+}
@@ -0,0 +1,59 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHandler(t *testing.T) {
+	first := &MockHandler{}
+	first.On("Handle", "GET", "/", mock.Anything).Return(Response{Body: []byte("welcome")})
+	returning := &MockHandlerWithRequest{}
+	returning.On("HandleRequest", mock.Anything).Return(Response{Body: []byte("welcome back")})
+	h := NewSessionHandler(first, returning)
+	s := NewServer(h)
+	defer s.Close()
+
+	get := func(client *http.Client) string {
+		resp, err := client.Get(s.URL() + "/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	withJar := &http.Client{Jar: jar}
+	assert.Equal(t, "welcome", get(withJar))
+	assert.Equal(t, "welcome back", get(withJar))
+	assert.Equal(t, "welcome back", get(withJar))
+	assert.Equal(t, "welcome", get(http.DefaultClient))
+
+	assert.Equal(t, []string{"session-1", "session-2"}, h.Sessions())
+	assert.Equal(t, 2, h.SessionRequests("session-1"))
+	assert.Equal(t, 0, h.SessionRequests("session-2"))
+	assert.Equal(t, 2, h.RequestsWithoutSession())
+
+	s.Reset()
+	assert.Equal(t, "welcome", get(withJar), "sessions should be forgotten")
+	assert.Equal(t, []string{"session-3"}, h.Sessions())
+}
+
+func TestSessionHandlerCookieTemplate(t *testing.T) {
+	h := NewSessionHandler(&OKHandler{}, &OKHandler{})
+	h.Cookie = &http.Cookie{Name: "sid", Path: "/app", Secure: true, MaxAge: 60}
+	resp := h.Handle("GET", "/app", nil)
+	assert.Equal(t, "sid=session-1; Path=/app; Max-Age=60; Secure", resp.Header.Get("Set-Cookie"))
+
+	resp = h.HandleRequest(RequestInfo{Header: http.Header{"Cookie": {"sid=session-1"}}})
+	assert.Empty(t, resp.Header.Get("Set-Cookie"))
+	resp = h.HandleRequest(RequestInfo{Header: http.Header{"Cookie": {"sid=forged"}}})
+	assert.Equal(t, "sid=session-2; Path=/app; Max-Age=60; Secure", resp.Header.Get("Set-Cookie"))
+}
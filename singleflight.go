@@ -0,0 +1,43 @@
+package httpmock
+
+import (
+	"time"
+)
+
+// AssertSingleFlight asserts that no two requests for method and path (ignoring the query string) received so far
+// overlapped in time, from when their headers were read until their response was written, e.g. to check that a client
+// deduplicates concurrent token refreshes. Requests still being handled are taken to last until now. It requires the
+// RecordHistory option.
+func (s *Server) AssertSingleFlight(t TestingT, method, path string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if s.history == nil {
+		t.Errorf("httpmock: AssertSingleFlight requires the RecordHistory option")
+		return false
+	}
+
+	now := time.Now()
+	var prev *RecordedRequest
+	var prevEnd time.Time
+	// The history is in arrival order, i.e. by Start
+	for _, req := range s.History() {
+		if req.Method != method || stripQuery(req.Path) != path {
+			continue
+		}
+		end := req.End
+		if end.IsZero() {
+			end = now
+		}
+		if prev != nil && req.Start.Before(prevEnd) {
+			t.Errorf("httpmock: %s %s requests overlapped: one started at %s, while the one started at %s was still "+
+				"being handled", method, path, req.Start.Format("15:04:05.000"), prev.Start.Format("15:04:05.000"))
+			return false
+		}
+		if prev == nil || end.After(prevEnd) {
+			req := req
+			prev, prevEnd = &req, end
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package httpmock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAssertSingleFlight(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "POST", "/token?x=1", mock.Anything).After(50 * time.Millisecond).Return(Response{})
+	downstream.On("Handle", "GET", "/token", mock.Anything).After(50 * time.Millisecond).Return(Response{})
+	s := NewServer(downstream, RecordHistory())
+	defer s.Close()
+
+	post := func(n int) {
+		done := make(chan struct{})
+		for i := 0; i < n; i++ {
+			go func() {
+				defer func() { done <- struct{}{} }()
+				if resp, err := s.Client().Post(s.URL()+"/token?x=1", "", nil); err == nil {
+					resp.Body.Close()
+				}
+			}()
+		}
+		for i := 0; i < n; i++ {
+			<-done
+		}
+	}
+	post(1)
+	post(1)
+	concurrentGets(s, "/token", 2)
+	rt := &recordingT{}
+	assert.True(t, s.AssertSingleFlight(rt, "POST", "/token"))
+	assert.Empty(t, rt.Errors())
+
+	post(2)
+	assert.False(t, s.AssertSingleFlight(rt, "POST", "/token"))
+	if assert.Len(t, rt.Errors(), 1) {
+		assert.Contains(t, rt.Errors()[0], "httpmock: POST /token requests overlapped")
+	}
+
+	assert.False(t, NewUnstartedServer(downstream).AssertSingleFlight(rt, "POST", "/token"))
+	assert.Contains(t, rt.Errors(), "httpmock: AssertSingleFlight requires the RecordHistory option")
+}
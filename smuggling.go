@@ -0,0 +1,178 @@
+package httpmock
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RawResponse returns a Response writing raw to the connection as is, instead of a response built by net/http, and
+// then closing it. This allows responses that net/http refuses to produce, such as ones with malformed or ambiguous
+// framing, for testing how clients and proxies parse them.
+func RawResponse(raw []byte) Response {
+	return Response{Hijack: func(conn net.Conn, rw *bufio.ReadWriter) {
+		rw.Write(raw)
+	}}
+}
+
+// ConflictingLengthsResponse returns a raw Response declaring both a Content-Length of contentLength and a chunked
+// Transfer-Encoding, the classic request smuggling setup. RFC 9112 requires recipients to ignore the Content-Length
+// (or reject the message), so the body is sent chunked. With a contentLength shorter than the chunked body, a client
+// honoring the Content-Length would read the rest as the start of the next response.
+func ConflictingLengthsResponse(status int, body []byte, contentLength int) Response {
+	var raw bytes.Buffer
+	fmt.Fprintf(&raw, "HTTP/1.1 %03d %s\r\n", status, http.StatusText(status))
+	fmt.Fprintf(&raw, "Content-Length: %d\r\nTransfer-Encoding: chunked\r\n\r\n", contentLength)
+	if len(body) > 0 {
+		fmt.Fprintf(&raw, "%x\r\n%s\r\n", len(body), body)
+	}
+	raw.WriteString("0\r\n\r\n")
+	return RawResponse(raw.Bytes())
+}
+
+// DuplicateContentLengthsResponse returns a raw Response declaring a Content-Length header for each of the given
+// lengths, followed by body. Clients must reject differing values, but may accept identical ones.
+func DuplicateContentLengthsResponse(status int, body []byte, lengths ...int) Response {
+	var raw bytes.Buffer
+	fmt.Fprintf(&raw, "HTTP/1.1 %03d %s\r\n", status, http.StatusText(status))
+	for _, length := range lengths {
+		fmt.Fprintf(&raw, "Content-Length: %d\r\n", length)
+	}
+	raw.WriteString("\r\n")
+	raw.Write(body)
+	return RawResponse(raw.Bytes())
+}
+
+// DuplicateHeaderResponse returns a raw Response with the given status and body, declaring its Content-Length and a
+// separate name header line for each of the given values, e.g. to see which of two Content-Type or Location headers
+// a client honors. net/http's client keeps all of them, in order, and most of its helpers use the first.
+func DuplicateHeaderResponse(status int, body []byte, name string, values ...string) Response {
+	var raw bytes.Buffer
+	fmt.Fprintf(&raw, "HTTP/1.1 %03d %s\r\n", status, http.StatusText(status))
+	for _, value := range values {
+		fmt.Fprintf(&raw, "%s: %s\r\n", name, value)
+	}
+	fmt.Fprintf(&raw, "Content-Length: %d\r\n\r\n", len(body))
+	raw.Write(body)
+	return RawResponse(raw.Bytes())
+}
+
+// DuplicateContentTypesResponse returns a raw Response with a Content-Type header for each of the given media types,
+// see DuplicateHeaderResponse.
+func DuplicateContentTypesResponse(status int, body []byte, mediaTypes ...string) Response {
+	return DuplicateHeaderResponse(status, body, "Content-Type", mediaTypes...)
+}
+
+// ParsePipelined splits raw into the requests that Go's HTTP/1.x parser, which the server uses, reads from it when
+// they are sent back to back on one connection. It returns the requests parsed before any error, e.g. to check where a
+// request with conflicting Content-Length and Transfer-Encoding headers ends.
+func ParsePipelined(raw []byte) ([]RequestInfo, error) {
+	var reqs []RequestInfo
+	br := bufio.NewReader(bytes.NewReader(raw))
+	for {
+		if _, err := br.Peek(1); err == io.EOF {
+			return reqs, nil
+		}
+		r, err := http.ReadRequest(br)
+		if err != nil {
+			return reqs, err
+		}
+		body, err := io.ReadAll(r.Body)
+		req := RequestInfo{
+			Method:  r.Method,
+			Path:    r.URL.RequestURI(),
+			Host:    r.Host,
+			Header:  r.Header,
+			Body:    body,
+			Proto:   r.Proto,
+			BodyErr: err,
+		}
+		reqs = append(reqs, req)
+		if err != nil {
+			return reqs, err
+		}
+	}
+}
+
+// SendRaw writes raw to a new connection to the server as is, e.g. several pipelined requests or a request with
+// ambiguous framing, and returns the responses the server sent back, in order, until it closed the connection. Along
+// with the RecordHistory option, this shows how the server's parser split the input. It returns the responses read
+// before any error.
+func (s *Server) SendRaw(raw []byte) ([]*http.Response, error) {
+	var conn net.Conn
+	var err error
+	if s.dial != nil {
+		conn, err = s.dial(context.Background())
+	} else {
+		conn, err = net.Dial("tcp", s.httpServer.Listener.Addr().String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.httpServer.TLS != nil {
+		conn = tls.Client(conn, s.httpServer.Client().Transport.(*http.Transport).TLSClientConfig)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write(raw); err != nil {
+		return nil, err
+	}
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		// Let the server close the connection once it has answered everything
+		_ = cw.CloseWrite()
+	}
+
+	// Each response is read in light of the request it answers, as the server's parser split them, since responses to
+	// HEAD requests have no body whatever their headers say
+	var reqs []*http.Request
+	for rr := bufio.NewReader(bytes.NewReader(raw)); ; {
+		r, err := http.ReadRequest(rr)
+		if err != nil {
+			break
+		}
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			break
+		}
+		reqs = append(reqs, r)
+	}
+
+	var resps []*http.Response
+	answered := 0
+	br := bufio.NewReader(conn)
+	for {
+		// Connections that can't be half-closed stay open, so give up after a second of silence
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := br.Peek(1); err != nil {
+			var netErr net.Error
+			if err == io.EOF || (errors.As(err, &netErr) && netErr.Timeout() && len(resps) > 0) {
+				return resps, nil
+			}
+			return resps, err
+		}
+		var req *http.Request
+		if answered < len(reqs) {
+			req = reqs[answered]
+		}
+		resp, err := http.ReadResponse(br, req)
+		if err != nil {
+			return resps, err
+		}
+		if resp.StatusCode >= 200 || resp.StatusCode == http.StatusSwitchingProtocols {
+			// Informational responses precede the final response to the same request
+			answered++
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resps = append(resps, resp)
+		if err != nil {
+			return resps, err
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package httpmock
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflictingLengthsResponse(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/te", mock.Anything).Return(ConflictingLengthsResponse(200, []byte("hello"), 2))
+	downstream.On("Handle", "GET", "/cl", mock.Anything).Return(DuplicateContentLengthsResponse(200, []byte("hi"), 2, 3))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	// Go's client follows RFC 9112 and reads the body as chunked
+	r, err := http.Get(s.URL() + "/te")
+	require.NoError(t, err)
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	_, err = http.Get(s.URL() + "/cl")
+	assert.Error(t, err)
+}
+
+func TestDuplicateHeaderResponse(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/", mock.Anything).Return(
+		DuplicateContentTypesResponse(200, []byte("{}"), "application/json", "text/html"))
+	s := NewServer(downstream)
+	defer s.Close()
+
+	r, err := http.Get(s.URL() + "/")
+	require.NoError(t, err)
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(body))
+	assert.Equal(t, []string{"application/json", "text/html"}, r.Header["Content-Type"])
+}
+
+func TestParsePipelined(t *testing.T) {
+	raw := "POST /a HTTP/1.1\r\nHost: x\r\nContent-Length: 4\r\nTransfer-Encoding: chunked\r\n\r\n" +
+		"0\r\n\r\n" +
+		"GET /b HTTP/1.1\r\nHost: x\r\n\r\n"
+	reqs, err := ParsePipelined([]byte(raw))
+	require.NoError(t, err)
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "/a", reqs[0].Path)
+	assert.Empty(t, reqs[0].Body)
+	assert.Equal(t, "/b", reqs[1].Path)
+
+	reqs, err = ParsePipelined([]byte("GET /a HTTP/1.1\r\nHost: x\r\n\r\nnonsense\r\n\r\n"))
+	assert.Error(t, err)
+	assert.Len(t, reqs, 1)
+}
+
+func TestSendRaw(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", mock.Anything, mock.Anything).Return(Response{Body: []byte("ok")})
+	for name, opts := range map[string][]Option{"tcp": {RecordHistory()}, "in memory": {RecordHistory(), InMemory()}} {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(downstream, opts...)
+			defer s.Close()
+
+			resps, err := s.SendRaw([]byte("GET /a HTTP/1.1\r\nHost: x\r\n\r\nGET /b HTTP/1.1\r\nHost: x\r\n\r\n"))
+			require.NoError(t, err)
+			require.Len(t, resps, 2)
+			body, _ := io.ReadAll(resps[1].Body)
+			assert.Equal(t, "ok", string(body))
+
+			history := s.History()
+			require.Len(t, history, 2)
+			assert.Equal(t, "/b", history[1].Path)
+		})
+	}
+}
+
+func TestSendRawHead(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "HEAD", "/a", mock.Anything).Return(Response{Header: http.Header{"Content-Length": {"5"}}})
+	downstream.On("Handle", "GET", "/b", mock.Anything).Return(Response{Body: []byte("ok")})
+	s := NewServer(downstream)
+	defer s.Close()
+
+	resps, err := s.SendRaw([]byte("HEAD /a HTTP/1.1\r\nHost: x\r\n\r\nGET /b HTTP/1.1\r\nHost: x\r\n\r\n"))
+	require.NoError(t, err)
+	require.Len(t, resps, 2)
+	assert.Equal(t, int64(5), resps[0].ContentLength)
+	body, _ := io.ReadAll(resps[0].Body)
+	assert.Empty(t, body)
+	body, _ = io.ReadAll(resps[1].Body)
+	assert.Equal(t, "ok", string(body))
+}
+
+func TestRawResponse(t *testing.T) {
+	resp := RawResponse([]byte("HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nhi"))
+	var buf bytes.Buffer
+	rw := bufio.NewReadWriter(bufio.NewReader(strings.NewReader("")), bufio.NewWriter(&buf))
+	resp.Hijack(nil, rw)
+	require.NoError(t, rw.Flush())
+	assert.Equal(t, "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nhi", buf.String())
+}
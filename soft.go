@@ -0,0 +1,101 @@
+package httpmock
+
+import (
+	"net/http"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// mismatch is a request that matched no expectation in soft assertions mode, with testify's explanation.
+type mismatch struct {
+	req RequestInfo
+	msg string
+}
+
+// SoftAssertions makes the server's handler, if it is one of the mock handlers of this package, collect the requests
+// that match no expectation instead of failing the test from the server's goroutine, where the failure is reported
+// with the stack of net/http rather than the test's. Such requests get a 500 response, and are all reported at once
+// by Verify, which the test must call:
+//
+//	s := httpmock.NewServer(downstream, httpmock.SoftAssertions())
+//	defer s.Verify(t)
+func SoftAssertions() Option {
+	return func(s *Server) {
+		if h, ok := s.handler.(interface{ SoftAssertions() }); ok {
+			h.SoftAssertions()
+		}
+	}
+}
+
+// Verify reports the mismatches collected in soft assertions mode, see SoftAssertions, and the panics collected with
+// the DeferFailures option, and asserts the expectations of the server's handler, if it is one of the mock handlers of
+// this package.
+func (s *Server) Verify(t TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ok := s.reportDeferred(t)
+	if v, isVerifier := s.handler.(interface{ Verify(t TestingT) bool }); isVerifier {
+		ok = v.Verify(t) && ok
+	}
+	return ok
+}
+
+// setSoft turns soft assertions on for m. testify only panics on mismatches when it has no test to fail.
+func (r *tracker) setSoft(m *mock.Mock) {
+	m.Test(nil)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.soft = true
+}
+
+func (r *tracker) isSoft() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.soft
+}
+
+// recordMismatch records the failure message of testify for req, returning the arguments of the response to send
+// instead.
+func (r *tracker) recordMismatch(req RequestInfo, msg string) mock.Arguments {
+	r.mu.Lock()
+	r.mismatches = append(r.mismatches, mismatch{req: req, msg: msg})
+	r.mu.Unlock()
+	return mock.Arguments{Response{
+		Status: http.StatusInternalServerError,
+		Body:   []byte("httpmock: no expectation matched: " + msg),
+	}}
+}
+
+// reportMismatches reports the mismatches collected so far to t, forgetting them, and reports whether there were none.
+func (r *tracker) reportMismatches(t TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	r.mu.Lock()
+	mismatches := r.mismatches
+	r.mismatches = nil
+	r.mu.Unlock()
+
+	for _, mm := range mismatches {
+		t.Errorf("httpmock: %s %s matched no expectation: %s", mm.req.Method, mm.req.Path, mm.msg)
+	}
+	return len(mismatches) == 0
+}
+
+// mockTestingT adapts a TestingT to testify's mock.TestingT.
+type mockTestingT struct {
+	TestingT
+}
+
+func (t mockTestingT) Logf(format string, args ...interface{}) {
+	if l, ok := t.TestingT.(interface{ Logf(string, ...interface{}) }); ok {
+		l.Logf(format, args...)
+	}
+}
+
+func (t mockTestingT) FailNow() {
+	if f, ok := t.TestingT.(interface{ FailNow() }); ok {
+		f.FailNow()
+	}
+}
@@ -0,0 +1,44 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftAssertions(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/a", mock.Anything).Return(Response{Status: 204})
+	downstream.On("Handle", "GET", "/never", mock.Anything).Return(Response{})
+	s := NewServer(downstream, SoftAssertions())
+	defer s.Close()
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		resp, err := http.Get(s.URL() + path)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if path == "/a" {
+			assert.Equal(t, 204, resp.StatusCode)
+		} else {
+			assert.Equal(t, 500, resp.StatusCode)
+			assert.Contains(t, string(body), "httpmock: no expectation matched: ")
+		}
+	}
+
+	rt := &recordingT{}
+	assert.False(t, s.Verify(rt))
+	errs := rt.Errors()
+	require.Len(t, errs, 3)
+	assert.True(t, strings.HasPrefix(errs[0], "httpmock: GET /b matched no expectation: "), errs[0])
+	assert.True(t, strings.HasPrefix(errs[1], "httpmock: GET /c matched no expectation: "), errs[1])
+	assert.Contains(t, errs[2], "needs to make 1 more call")
+
+	s.Reset()
+	assert.True(t, s.Verify(t))
+}
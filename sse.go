@@ -0,0 +1,110 @@
+package httpmock
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEEvent is one server-sent event, see SSEResponse.
+type SSEEvent struct {
+	// The event ID, which the client sends back in Last-Event-ID when it reconnects (optional)
+	ID string
+	// The event type (optional, clients default to "message")
+	Event string
+	// The event data; multiple lines are sent as multiple data fields
+	Data string
+	// The reconnection time to advise the client (optional)
+	Retry time.Duration
+}
+
+// SSEResponse returns a Response sending the given events as a text/event-stream. The stream ends after the last
+// event, as if the server had closed the connection, so clients are expected to reconnect.
+func SSEResponse(events ...SSEEvent) Response {
+	var buf bytes.Buffer
+	for _, e := range events {
+		if e.ID != "" {
+			buf.WriteString("id: " + e.ID + "\n")
+		}
+		if e.Event != "" {
+			buf.WriteString("event: " + e.Event + "\n")
+		}
+		if e.Retry > 0 {
+			buf.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+		}
+		for _, line := range strings.Split(e.Data, "\n") {
+			buf.WriteString("data: " + line + "\n")
+		}
+		buf.WriteString("\n")
+	}
+	return Response{
+		Header: http.Header{
+			"Content-Type":  {"text/event-stream"},
+			"Cache-Control": {"no-cache"},
+		},
+		Body: buf.Bytes(),
+	}
+}
+
+// SSEHandler is a HandlerWithHeaders serving a scripted stream of server-sent events to every request. It honors the
+// Last-Event-ID header clients send when reconnecting, resuming the stream after that event, so client reconnect logic
+// can be tested realistically.
+type SSEHandler struct {
+	// The scripted events. Events should have IDs for resuming to work.
+	Events []SSEEvent
+	// EventsPerConnection, if non-zero, limits how many events are sent before the stream ends, forcing the client
+	// to reconnect to get the rest.
+	EventsPerConnection int
+
+	mu           sync.Mutex
+	lastEventIDs []string
+}
+
+// LastEventIDs returns the Last-Event-ID header of every request received so far ("" for requests without one), so
+// tests can assert on how the client reconnected.
+func (h *SSEHandler) LastEventIDs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.lastEventIDs...)
+}
+
+// Reset forgets the Last-Event-ID headers received so far, see Server.Reset.
+func (h *SSEHandler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastEventIDs = nil
+}
+
+// Handle makes this implement the Handler interface.
+func (h *SSEHandler) Handle(method, path string, body []byte) Response {
+	return h.HandleWithHeaders(method, path, http.Header{}, body)
+}
+
+// HandleWithHeaders makes this implement the HandlerWithHeaders interface.
+func (h *SSEHandler) HandleWithHeaders(method, path string, headers http.Header, body []byte) Response {
+	lastEventID := headers.Get("Last-Event-ID")
+	h.mu.Lock()
+	h.lastEventIDs = append(h.lastEventIDs, lastEventID)
+	h.mu.Unlock()
+
+	events := h.Events
+	if lastEventID != "" {
+		for i, e := range events {
+			if e.ID == lastEventID {
+				events = events[i+1:]
+				break
+			}
+		}
+	}
+	if len(events) == 0 {
+		// Per the SSE spec, 204 tells the client to stop reconnecting
+		return Response{Status: http.StatusNoContent}
+	}
+	if h.EventsPerConnection > 0 && len(events) > h.EventsPerConnection {
+		events = events[:h.EventsPerConnection]
+	}
+	return SSEResponse(events...)
+}
@@ -0,0 +1,48 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEHandler(t *testing.T) {
+	h := &SSEHandler{
+		Events: []SSEEvent{
+			{ID: "1", Data: "first"},
+			{ID: "2", Event: "update", Data: "second\nline"},
+			{ID: "3", Data: "third"},
+		},
+		EventsPerConnection: 2,
+	}
+	s := NewServer(h)
+	defer s.Close()
+
+	get := func(lastEventID string) (int, string) {
+		req, err := http.NewRequest("GET", s.URL()+"/events", nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "id: 1\ndata: first\n\nid: 2\nevent: update\ndata: second\ndata: line\n\n", body)
+
+	_, body = get("2")
+	assert.Equal(t, "id: 3\ndata: third\n\n", body)
+
+	status, _ = get("3")
+	assert.Equal(t, http.StatusNoContent, status)
+
+	assert.Equal(t, []string{"", "2", "3"}, h.LastEventIDs())
+}
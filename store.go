@@ -0,0 +1,180 @@
+package httpmock
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Store is an in-memory key/value and resource store that the handlers of several servers can share, so that a fake
+// environment stays consistent across services, e.g. an auth server issues a token that the catalog server then
+// accepts. It is safe for concurrent use. Matchers and computed responses read and update it, see Matcher and
+// Response; a Scenario has one, see Scenario.Store.
+//
+//	store := sc.Store()
+//	issue := func(st *httpmock.Store, req httpmock.RequestInfo) httpmock.Response {
+//		token := st.Create("tokens", req.Header.Get("X-User"))
+//		return httpmock.Response{BodyObject: map[string]string{"access_token": token}}
+//	}
+//	auth.On("HandleRequest", mock.Anything).Return(store.Response(issue))
+//	catalog.On("HandleRequest", store.Matcher(func(st *httpmock.Store, req httpmock.RequestInfo) bool {
+//		_, ok := st.Fetch("tokens", strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+//		return ok
+//	})).Return(httpmock.Response{Body: []byte("[]")})
+type Store struct {
+	mu          sync.Mutex
+	values      map[string]interface{}
+	collections map[string]*storeCollection
+}
+
+// storeCollection is a collection of resources of a Store, kept in the order they were added.
+type storeCollection struct {
+	ids     []string
+	objects map[string]interface{}
+	lastID  int
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Set sets the value of key.
+func (st *Store) Set(key string, value interface{}) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.values == nil {
+		st.values = make(map[string]interface{})
+	}
+	st.values[key] = value
+}
+
+// Get returns the value of key, and whether it is set.
+func (st *Store) Get(key string) (interface{}, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	value, ok := st.values[key]
+	return value, ok
+}
+
+// Delete unsets key.
+func (st *Store) Delete(key string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.values, key)
+}
+
+// Create adds obj to the collection under a new ID, "1", "2" and so on, skipping the IDs already taken, and returns
+// the ID.
+func (st *Store) Create(collection string, obj interface{}) string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	c := st.collection(collection)
+	for {
+		c.lastID++
+		id := strconv.Itoa(c.lastID)
+		if _, taken := c.objects[id]; !taken {
+			c.put(id, obj)
+			return id
+		}
+	}
+}
+
+// Put adds obj to the collection under id, replacing any resource with that ID.
+func (st *Store) Put(collection, id string, obj interface{}) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.collection(collection).put(id, obj)
+}
+
+// Fetch returns the resource of the collection with the given id, and whether there is one.
+func (st *Store) Fetch(collection, id string) (interface{}, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	c := st.collections[collection]
+	if c == nil {
+		return nil, false
+	}
+	obj, ok := c.objects[id]
+	return obj, ok
+}
+
+// List returns the resources of the collection, in the order they were added.
+func (st *Store) List(collection string) []interface{} {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	c := st.collections[collection]
+	if c == nil {
+		return nil
+	}
+	objs := make([]interface{}, len(c.ids))
+	for i, id := range c.ids {
+		objs[i] = c.objects[id]
+	}
+	return objs
+}
+
+// Remove removes the resource of the collection with the given id, reporting whether there was one.
+func (st *Store) Remove(collection, id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	c := st.collections[collection]
+	if c == nil {
+		return false
+	}
+	if _, ok := c.objects[id]; !ok {
+		return false
+	}
+	delete(c.objects, id)
+	for i, other := range c.ids {
+		if other == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Reset empties the store. Unlike the state of handlers, it isn't cleared by Server.Reset, since it is shared.
+func (st *Store) Reset() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.values = nil
+	st.collections = nil
+}
+
+// Matcher returns a mock.MatchedBy func to check the RequestInfo argument of HandleRequest with fn, which can read the
+// store, e.g. to only accept tokens that another server issued.
+func (st *Store) Matcher(fn func(st *Store, req RequestInfo) bool) interface{} {
+	return mock.MatchedBy(func(req RequestInfo) bool {
+		return fn(st, req)
+	})
+}
+
+// Response returns a Response that is computed by fn when it is served, which can read and update the store.
+func (st *Store) Response(fn func(st *Store, req RequestInfo) Response) Response {
+	return Response{compute: func(req RequestInfo) Response {
+		return fn(st, req)
+	}}
+}
+
+// collection returns the collection with the given name, creating it if needed, with st.mu held.
+func (st *Store) collection(name string) *storeCollection {
+	if st.collections == nil {
+		st.collections = make(map[string]*storeCollection)
+	}
+	c := st.collections[name]
+	if c == nil {
+		c = &storeCollection{objects: make(map[string]interface{})}
+		st.collections[name] = c
+	}
+	return c
+}
+
+func (c *storeCollection) put(id string, obj interface{}) {
+	if _, ok := c.objects[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.objects[id] = obj
+}
@@ -0,0 +1,81 @@
+package httpmock
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	st := NewStore()
+	st.Set("mode", "maintenance")
+	v, ok := st.Get("mode")
+	assert.True(t, ok)
+	assert.Equal(t, "maintenance", v)
+	st.Delete("mode")
+	_, ok = st.Get("mode")
+	assert.False(t, ok)
+
+	st.Put("users", "2", "bob")
+	assert.Equal(t, "1", st.Create("users", "alice"))
+	assert.Equal(t, "3", st.Create("users", "carol"), "taken IDs should be skipped")
+	st.Put("users", "2", "robert")
+	assert.Equal(t, []interface{}{"robert", "alice", "carol"}, st.List("users"))
+	obj, ok := st.Fetch("users", "3")
+	assert.True(t, ok)
+	assert.Equal(t, "carol", obj)
+	assert.True(t, st.Remove("users", "1"))
+	assert.False(t, st.Remove("users", "1"))
+	assert.False(t, st.Remove("groups", "1"))
+	_, ok = st.Fetch("groups", "1")
+	assert.False(t, ok)
+	assert.Equal(t, []interface{}{"robert", "carol"}, st.List("users"))
+
+	st.Reset()
+	assert.Nil(t, st.List("users"))
+}
+
+func TestScenarioStore(t *testing.T) {
+	sc := NewScenario()
+	store := sc.Store()
+
+	auth := &MockHandlerWithRequest{}
+	auth.On("HandleRequest", mock.Anything).Return(store.Response(func(st *Store, req RequestInfo) Response {
+		return Response{Body: []byte(st.Create("tokens", req.Header.Get("X-User")))}
+	}))
+	catalog := &MockHandlerWithRequest{}
+	catalog.On("HandleRequest", store.Matcher(func(st *Store, req RequestInfo) bool {
+		_, ok := st.Fetch("tokens", strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+		return ok
+	})).Return(Response{Body: []byte("[]")})
+	catalog.On("HandleRequest", mock.Anything).Return(Response{Status: http.StatusUnauthorized})
+	sc.Add("auth", auth)
+	sc.Add("catalog", catalog)
+	sc.Start()
+	defer sc.Close()
+
+	get := func(url, header, value string) (int, string) {
+		req, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err)
+		req.Header.Set(header, value)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+	status, _ := get(sc.URLs()["catalog"]+"/items", "Authorization", "Bearer 1")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	_, token := get(sc.URLs()["auth"]+"/token", "X-User", "alice")
+	status, body := get(sc.URLs()["catalog"]+"/items", "Authorization", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "[]", body)
+	user, _ := store.Fetch("tokens", token)
+	assert.Equal(t, "alice", user)
+}
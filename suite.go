@@ -0,0 +1,72 @@
+package httpmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// SuiteServer is a Server shared by the tests of a testify suite, with each test starting from a clean handler. Call
+// its SetupTest, TearDownTest and TearDownSuite methods from the suite's hooks of the same names:
+//
+//	type ClientSuite struct {
+//		suite.Suite
+//		downstream httpmock.SuiteServer
+//	}
+//
+//	func (s *ClientSuite) SetupTest()     { s.downstream.SetupTest(s.T()) }
+//	func (s *ClientSuite) TearDownTest()  { s.downstream.TearDownTest(s.T()) }
+//	func (s *ClientSuite) TearDownSuite() { s.downstream.TearDownSuite() }
+//
+//	func (s *ClientSuite) TestGet() {
+//		s.downstream.On("Handle", "GET", "/object/12345", mock.Anything).Return(httpmock.Response{Status: 204})
+//		// ... make the code under test call s.downstream.URL()
+//	}
+type SuiteServer struct {
+	*Server
+	// The handler, a MockHandler unless set before the first SetupTest. It is reset before every test.
+	Handler Handler
+	// The options of the server, used when the first SetupTest creates it
+	Options []Option
+}
+
+// SetupTest creates and starts the server on the first call, and resets it (see Server.Reset) on later ones, so that
+// each test registers its own expectations. Mock handlers report their failures to t.
+func (s *SuiteServer) SetupTest(t *testing.T) {
+	if s.Server == nil {
+		if s.Handler == nil {
+			s.Handler = &MockHandler{}
+		}
+		s.Server = NewServer(s.Handler, s.Options...)
+	} else {
+		s.Server.Reset()
+	}
+	if h, ok := s.Handler.(interface{ Test(mock.TestingT) }); ok {
+		h.Test(t)
+	}
+}
+
+// TearDownTest asserts the expectations of the test, if the handler is a mock handler.
+func (s *SuiteServer) TearDownTest(t *testing.T) {
+	t.Helper()
+	if h, ok := s.Handler.(interface{ AssertExpectations(mock.TestingT) bool }); ok {
+		h.AssertExpectations(t)
+	}
+}
+
+// TearDownSuite closes the server. A later SetupTest would create a new one.
+func (s *SuiteServer) TearDownSuite() {
+	if s.Server != nil {
+		s.Server.Close()
+		s.Server = nil
+	}
+}
+
+// On registers an expectation on the handler, which must be a Mocker such as a MockHandler.
+func (s *SuiteServer) On(methodName string, arguments ...interface{}) *mock.Call {
+	m, ok := s.Handler.(Mocker)
+	if !ok {
+		panic("httpmock: the suite server's handler doesn't support expectations")
+	}
+	return m.On(methodName, arguments...)
+}
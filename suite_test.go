@@ -0,0 +1,54 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteServerSuite struct {
+	suite.Suite
+	downstream SuiteServer
+	server     *Server
+}
+
+func (s *suiteServerSuite) SetupTest()     { s.downstream.SetupTest(s.T()) }
+func (s *suiteServerSuite) TearDownTest()  { s.downstream.TearDownTest(s.T()) }
+func (s *suiteServerSuite) TearDownSuite() { s.downstream.TearDownSuite() }
+
+func (s *suiteServerSuite) get(path string) int {
+	resp, err := http.Get(s.downstream.URL() + path)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func (s *suiteServerSuite) TestA() {
+	s.checkSameServer()
+	s.downstream.On("Handle", "GET", "/a", mock.Anything).Return(Response{Status: 201})
+	s.Equal(201, s.get("/a"))
+}
+
+func (s *suiteServerSuite) TestB() {
+	s.checkSameServer()
+	// The expectation of TestA is gone
+	s.downstream.On("Handle", "GET", "/a", mock.Anything).Return(Response{Status: 202})
+	s.Equal(202, s.get("/a"))
+}
+
+func (s *suiteServerSuite) checkSameServer() {
+	if s.server == nil {
+		s.server = s.downstream.Server
+	}
+	s.Same(s.server, s.downstream.Server)
+}
+
+func TestSuiteServer(t *testing.T) {
+	st := &suiteServerSuite{}
+	suite.Run(t, st)
+	if st.downstream.Server != nil {
+		t.Error("the server wasn't closed")
+	}
+}
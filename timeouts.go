@@ -0,0 +1,51 @@
+package httpmock
+
+import (
+	"net/http"
+	"time"
+)
+
+// serverTimeouts are the timeouts of the underlying http.Server, zero meaning none as in http.Server.
+type serverTimeouts struct {
+	read, readHeader, write, idle time.Duration
+}
+
+func (t serverTimeouts) apply(config *http.Server) {
+	config.ReadTimeout = t.read
+	config.ReadHeaderTimeout = t.readHeader
+	config.WriteTimeout = t.write
+	config.IdleTimeout = t.idle
+}
+
+// ReadTimeout sets the maximum duration for reading an entire request, including the body, see http.Server. Clients
+// that send too slowly get their connection closed, which makes slow uploads testable.
+func ReadTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.timeouts.read = d
+	}
+}
+
+// ReadHeaderTimeout sets the maximum duration for reading a request's headers, see http.Server. Clients that send
+// headers too slowly get their connection closed without a response.
+func ReadHeaderTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.timeouts.readHeader = d
+	}
+}
+
+// WriteTimeout sets the maximum duration from the end of reading a request's headers to the end of writing its
+// response, see http.Server. Responses that take longer, e.g. with a delaying middleware, are cut off and the
+// connection closed, which clients see as an unexpected EOF.
+func WriteTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.timeouts.write = d
+	}
+}
+
+// IdleTimeout sets the maximum duration a keep-alive connection stays open while waiting for the next request, see
+// http.Server, e.g. for testing that clients retry requests on pooled connections that the server has closed.
+func IdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.timeouts.idle = d
+	}
+}
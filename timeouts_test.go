@@ -0,0 +1,46 @@
+package httpmock
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeaderTimeout(t *testing.T) {
+	downstream := &MockHandler{}
+	s := NewServer(downstream, ReadHeaderTimeout(50*time.Millisecond))
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.httpServer.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	assert.Equal(t, io.ErrUnexpectedEOF, err, "the server should have closed the connection without a response")
+	downstream.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWriteTimeout(t *testing.T) {
+	downstream := &MockHandler{}
+	downstream.On("Handle", "GET", "/slow", mock.Anything).After(200 * time.Millisecond).Return(Response{
+		Body: []byte("too late"),
+	})
+	s := NewServer(downstream, WriteTimeout(50*time.Millisecond))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/slow")
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package httpmock
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// NewTLSServer constructs a new server and starts it with TLS (compare to httptest.NewTLSServer). Use the server's
+// Client method for a client that trusts its certificate.
+func NewTLSServer(handler Handler, opts ...Option) *Server {
+	s := NewUnstartedServer(handler, opts...)
+	s.StartTLS()
+	return s
+}
+
+// StartTLS starts an unstarted server with TLS. The server's TLS config, e.g. to request client certificates, can be set
+// with the TLSConfig option.
+func (s *Server) StartTLS() {
+	s.httpServer.StartTLS()
+	close(s.ready)
+}
+
+// TLSConfig sets the TLS config used by StartTLS and NewTLSServer, like setting httptest.Server.TLS. The test server's
+// certificate is added to it if it has none.
+//
+//	s := httpmock.NewTLSServer(downstream, httpmock.TLSConfig(&tls.Config{ClientAuth: tls.RequireAnyClientCert}))
+func TLSConfig(config *tls.Config) Option {
+	return func(s *Server) {
+		s.tlsConfig = config
+	}
+}
+
+// ClientCertFingerprintMatcher returns a mock.MatchedBy func to check if the RequestInfo argument of HandleRequest was
+// made with a client certificate whose SHA-256 fingerprint is the given hex string. Colons between the bytes, as
+// printed by openssl, are allowed. Requests without a client certificate don't match.
+func ClientCertFingerprintMatcher(sha256hex string) interface{} {
+	sha256hex = strings.ToLower(strings.ReplaceAll(sha256hex, ":", ""))
+	return RequestMatcher(func(req RequestInfo) bool {
+		cert := clientCert(req)
+		if cert == nil {
+			return false
+		}
+		sum := sha256.Sum256(cert.Raw)
+		return hex.EncodeToString(sum[:]) == sha256hex
+	})
+}
+
+// ClientCertCNMatcher returns a mock.MatchedBy func to check if the RequestInfo argument of HandleRequest was made
+// with a client certificate whose subject common name is cn. Requests without a client certificate don't match.
+//
+//	downstream.On("HandleRequest", httpmock.ClientCertCNMatcher("billing-service"))
+func ClientCertCNMatcher(cn string) interface{} {
+	return RequestMatcher(func(req RequestInfo) bool {
+		cert := clientCert(req)
+		return cert != nil && cert.Subject.CommonName == cn
+	})
+}
+
+// clientCert returns the leaf certificate presented by the client, if any.
+func clientCert(req RequestInfo) *x509.Certificate {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return req.TLS.PeerCertificates[0]
+}
+
+// AssertMinTLSVersion asserts that every request the server received was made over TLS with at least the given
+// version, e.g. tls.VersionTLS12. The server must have been created with the RecordHistory option.
+func (s *Server) AssertMinTLSVersion(t TestingT, version uint16) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if s.history == nil {
+		t.Errorf("httpmock: AssertMinTLSVersion requires the RecordHistory option")
+		return false
+	}
+
+	ok := true
+	for _, req := range s.History() {
+		switch {
+		case req.TLS == nil:
+			t.Errorf("httpmock: %s %s was not made over TLS", req.Method, req.Path)
+			ok = false
+		case req.TLS.Version < version:
+			t.Errorf("httpmock: %s %s was made over %s, expected at least %s", req.Method, req.Path,
+				tlsVersionName(req.TLS.Version), tlsVersionName(version))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// tlsVersionName returns the name of a TLS version, like tls.VersionName does in newer Go versions.
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
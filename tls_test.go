@@ -0,0 +1,90 @@
+package httpmock
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertMinTLSVersion(t *testing.T) {
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "GET", "/secure", mock.Anything).Return(Response{})
+
+	s := NewTLSServer(downstream, RecordHistory())
+	defer s.Close()
+
+	client := s.Client()
+	client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+	resp, err := client.Get(fmt.Sprintf("%s/secure", s.URL()))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Len(t, s.History(), 1)
+	state := s.History()[0].TLS
+	require.NotNil(t, state)
+	assert.Equal(t, uint16(tls.VersionTLS12), state.Version)
+	assert.NotZero(t, state.CipherSuite)
+
+	assert.True(t, s.AssertMinTLSVersion(t, tls.VersionTLS12))
+	reporter := &recordingT{}
+	assert.False(t, s.AssertMinTLSVersion(reporter, tls.VersionTLS13))
+	assert.Equal(t, []string{"httpmock: GET /secure was made over TLS 1.2, expected at least TLS 1.3"}, reporter.Errors())
+
+	downstream.AssertExpectations(t)
+}
+
+func TestClientCertMatchers(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "billing-service"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	sum := sha256.Sum256(der)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	downstream := NewMockHandlerWithRequest(t)
+	downstream.On("HandleRequest", ClientCertCNMatcher("billing-service")).Return(Response{Status: 200}).Once()
+	downstream.On("HandleRequest", ClientCertFingerprintMatcher(strings.ToUpper(fingerprint))).Return(Response{Status: 201}).Once()
+	downstream.On("HandleRequest", mock.Anything).Return(Response{Status: 403})
+
+	s := NewTLSServer(downstream, TLSConfig(&tls.Config{ClientAuth: tls.RequestClientCert}))
+	defer s.Close()
+
+	resp, err := s.Client().Get(s.URL())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	transport := s.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.Certificates = []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}
+	client := &http.Client{Transport: transport}
+	for _, status := range []int{200, 201} {
+		resp, err = client.Get(s.URL())
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, status, resp.StatusCode)
+	}
+
+	downstream.AssertExpectations(t)
+}
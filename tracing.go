@@ -0,0 +1,156 @@
+package httpmock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TraceContext is the W3C trace context of a request, as carried by its traceparent and tracestate headers.
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	State   string
+}
+
+// Sampled returns whether the sampled flag is set.
+func (c TraceContext) Sampled() bool {
+	return c.Flags&1 == 1
+}
+
+// Traceparent returns the traceparent header value for the context.
+func (c TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%x-%x-%02x", c.TraceID, c.SpanID, c.Flags)
+}
+
+// ParseTraceContext returns the trace context of a request with the given headers, reporting whether it had a valid
+// traceparent header.
+func ParseTraceContext(header http.Header) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header.Get("Traceparent")), "-")
+	// Future versions may append fields
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return TraceContext{}, false
+	}
+	var c TraceContext
+	var flags [1]byte
+	if !decodeHexID(c.TraceID[:], parts[1]) || !decodeHexID(c.SpanID[:], parts[2]) || !decodeHexID(flags[:], parts[3]) {
+		return TraceContext{}, false
+	}
+	c.Flags = flags[0]
+	c.State = strings.Join(header.Values("Tracestate"), ",")
+	return c, true
+}
+
+// decodeHexID decodes the lowercase hex s into id, reporting whether it is valid, i.e. of the right length and not all
+// zeros (except for flags).
+func decodeHexID(id []byte, s string) bool {
+	if len(s) != 2*len(id) || strings.ToLower(s) != s {
+		return false
+	}
+	if _, err := hex.Decode(id, []byte(s)); err != nil {
+		return false
+	}
+	if len(id) == 1 {
+		return true
+	}
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Span is a server span covering the handling of one request, see TraceRequests. Its fields follow the OpenTelemetry
+// data model, but it is a plain struct that this package doesn't convert to the types of the OpenTelemetry SDK.
+type Span struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	// The span of the client that sent the request, from its traceparent header, zero if it had none
+	ParentSpanID [8]byte
+	// The flags and trace state propagated from the client, sampled if it sent no trace context
+	Flags      byte
+	TraceState string
+	// The method and path of the request without the query string, e.g. "GET /orders"
+	Name       string
+	Start, End time.Time
+	// Attributes following the OpenTelemetry semantic conventions for HTTP servers, such as "http.request.method"
+	// and "http.response.status_code"
+	Attributes map[string]interface{}
+	// Whether the response had a 5xx status, which makes the span status an error as per the semantic conventions
+	Error bool
+}
+
+// TraceRequests makes the server create a server span for every request handled, passing it to export once the
+// response is ready. Spans continue the trace of the request's traceparent header if it has one, so that the traces
+// of integration tests include the mocked downstream hop; otherwise they start a new trace. export typically collects
+// them for the test to inspect. Spans cover the middleware and the handler, and not the requests answered without
+// them, such as the ignored paths.
+//
+//	var spans []httpmock.Span
+//	var mu sync.Mutex
+//	s := httpmock.NewServer(downstream, httpmock.TraceRequests(func(span httpmock.Span) {
+//		mu.Lock()
+//		defer mu.Unlock()
+//		spans = append(spans, span)
+//	}))
+func TraceRequests(export func(span Span)) Option {
+	return func(s *Server) {
+		// Outermost, so that the span covers all the middleware
+		s.middleware = append([]Middleware{traceMiddleware(export)}, s.middleware...)
+	}
+}
+
+func traceMiddleware(export func(span Span)) Middleware {
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		path, _, _ := strings.Cut(req.Path, "?")
+		span := Span{Flags: 1, Name: req.Method + " " + path, Start: time.Now()}
+		if parent, ok := ParseTraceContext(req.Header); ok {
+			span.TraceID, span.ParentSpanID, span.Flags, span.TraceState = parent.TraceID, parent.SpanID, parent.Flags,
+				parent.State
+		} else {
+			_, _ = rand.Read(span.TraceID[:])
+		}
+		_, _ = rand.Read(span.SpanID[:])
+
+		resp := next(req)
+
+		span.End = time.Now()
+		status := responseStatus(resp)
+		span.Error = status >= 500
+		span.Attributes = spanAttributes(req, status)
+		export(span)
+		return resp
+	}
+}
+
+func spanAttributes(req RequestInfo, status int) map[string]interface{} {
+	path, query, _ := strings.Cut(req.Path, "?")
+	attrs := map[string]interface{}{
+		"http.request.method": req.Method,
+		"url.path":            path,
+		"server.address":      req.Host,
+	}
+	if query != "" {
+		attrs["url.query"] = query
+	}
+	if status != 0 {
+		attrs["http.response.status_code"] = status
+	}
+	if req.Proto != "" {
+		attrs["network.protocol.version"] = strings.TrimPrefix(req.Proto, "HTTP/")
+	}
+	if host, port, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		attrs["client.address"] = host
+		attrs["client.port"] = port
+	}
+	if ua := req.Header.Get("User-Agent"); ua != "" {
+		attrs["user_agent.original"] = ua
+	}
+	return attrs
+}
@@ -0,0 +1,79 @@
+package httpmock
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceContext(t *testing.T) {
+	c, ok := ParseTraceContext(http.Header{
+		"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"Tracestate":  {"a=1", "b=2"},
+	})
+	require.True(t, ok)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", c.Traceparent())
+	assert.True(t, c.Sampled())
+	assert.Equal(t, "a=1,b=2", c.State)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, ok := ParseTraceContext(http.Header{"Traceparent": {invalid}})
+		assert.False(t, ok, invalid)
+	}
+	_, ok = ParseTraceContext(http.Header{"Traceparent": {"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"}})
+	assert.True(t, ok, "future versions may have more fields")
+}
+
+func TestTraceRequests(t *testing.T) {
+	var mu sync.Mutex
+	var spans []Span
+	s := NewServer(&OKHandler{}, TraceRequests(func(span Span) {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, span)
+	}))
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", s.URL()+"/orders?page=2", nil)
+	require.NoError(t, err)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	resp, err = http.Get(s.URL() + "/other")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	c, _ := ParseTraceContext(req.Header)
+	assert.Equal(t, c.TraceID, span.TraceID)
+	assert.Equal(t, c.SpanID, span.ParentSpanID)
+	assert.NotEqual(t, [8]byte{}, span.SpanID)
+	assert.Equal(t, byte(0), span.Flags)
+	assert.Equal(t, "GET /orders", span.Name)
+	assert.False(t, span.End.Before(span.Start))
+	assert.Equal(t, "GET", span.Attributes["http.request.method"])
+	assert.Equal(t, "/orders", span.Attributes["url.path"])
+	assert.Equal(t, "page=2", span.Attributes["url.query"])
+	assert.Equal(t, 200, span.Attributes["http.response.status_code"])
+	assert.Equal(t, "127.0.0.1", span.Attributes["client.address"])
+	assert.False(t, span.Error)
+
+	assert.NotEqual(t, c.TraceID, spans[1].TraceID, "a request without trace context should start a new trace")
+	assert.Equal(t, [8]byte{}, spans[1].ParentSpanID)
+	assert.Equal(t, byte(1), spans[1].Flags)
+}
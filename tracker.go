@@ -0,0 +1,283 @@
+package httpmock
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// tracker registers the expectations of a mock handler, detecting the ones shadowed by an identical earlier
+// expectation that can match any number of times, since testify would silently never use them, and tracks which
+// requests they matched for Report.
+type tracker struct {
+	mu        sync.Mutex
+	strict    TestingT
+	logger    Logger
+	usage     map[*mock.Call]*ExpectationUsage
+	callbacks map[*mock.Call][]func(req RequestInfo)
+	handled   map[string]int
+
+	// The first maxUnmatched unmatched requests, and how many there were in all
+	unmatched      []RequestInfo
+	unmatchedCount int
+
+	// Set by SoftAssertions
+	soft       bool
+	mismatches []mismatch
+}
+
+// on registers an expectation on m like m.On, after checking that it isn't shadowed. Expectations chained with
+// mock.Call.On go to m.On directly and aren't checked themselves, but later ones are still checked against them.
+func (r *tracker) on(m *mock.Mock, methodName string, args []interface{}) *mock.Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range expectations(m) {
+		// Expectations limited with Once or Times leave room for the next ones
+		if e.method != methodName || e.repeatability != 0 || !sameArguments(e.arguments, args) {
+			continue
+		}
+		msg := fmt.Sprintf("expectation %s(%s) is shadowed by an identical one registered before it, so it will never "+
+			"be used", methodName, formatArgs(args))
+		if r.strict != nil {
+			if h, ok := r.strict.(interface{ Helper() }); ok {
+				h.Helper()
+			}
+			r.strict.Errorf("httpmock: %s", msg)
+		} else {
+			r.log().Warn("httpmock: " + msg)
+		}
+		break
+	}
+	return m.On(methodName, args...)
+}
+
+func (r *tracker) setStrict(t TestingT) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strict = t
+}
+
+func (r *tracker) setLogger(l Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger = l
+}
+
+// log returns the Logger for warnings, with r.mu held.
+func (r *tracker) log() Logger {
+	if r.logger == nil {
+		return stdLogger{}
+	}
+	return r.logger
+}
+
+// called calls methodName on m like m.Called, recording which expectation matched req. testify doesn't tell, but it
+// returns the matching expectation's ReturnArguments slice as is. When no expectation matches, testify fails the
+// test or panics, so the call never returns.
+func (r *tracker) called(m *mock.Mock, methodName string, req RequestInfo, args ...interface{}) (ret mock.Arguments) {
+	returned := false
+	defer func() {
+		if !returned {
+			r.recordUnmatched(req)
+			return
+		}
+		for _, fn := range r.recordMatch(m, req, ret) {
+			fn(req)
+		}
+	}()
+	if r.isSoft() {
+		defer func() {
+			if v := recover(); v != nil {
+				msg, isMismatch := v.(string)
+				if !isMismatch || !strings.Contains(msg, "mock: ") {
+					// A panic of the expectation's Run function, for instance
+					panic(v)
+				}
+				ret = r.recordMismatch(req, msg)
+			}
+		}()
+	}
+	ret = m.MethodCalled(methodName, args...)
+	returned = true
+	return ret
+}
+
+// maxUnmatched is how many unmatched requests a tracker keeps for Report.
+const maxUnmatched = 100
+
+func (r *tracker) recordUnmatched(req RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unmatchedCount++
+	if len(r.unmatched) < maxUnmatched {
+		r.unmatched = append(r.unmatched, req)
+	}
+}
+
+// recordMatch records that req matched the expectation of m returning ret, returning its OnMatch callbacks.
+func (r *tracker) recordMatch(m *mock.Mock, req RequestInfo, ret mock.Arguments) []func(req RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handled == nil {
+		r.handled = make(map[string]int)
+	}
+	r.handled[req.Method+" "+req.Path]++
+	for _, e := range expectations(m) {
+		if len(ret) == 0 || len(e.returns) != len(ret) || &e.returns[0] != &ret[0] {
+			continue
+		}
+		if r.usage == nil {
+			r.usage = make(map[*mock.Call]*ExpectationUsage)
+		}
+		usage := r.usage[e.call]
+		if usage == nil {
+			sample := req
+			usage = &ExpectationUsage{FirstMatch: time.Now(), Sample: &sample}
+			r.usage[e.call] = usage
+		}
+		usage.Matched++
+		usage.LastMatch = time.Now()
+		return append(([]func(req RequestInfo))(nil), r.callbacks[e.call]...)
+	}
+	return nil
+}
+
+// handledCount returns how many requests for method and path matched an expectation and were handled.
+func (r *tracker) handledCount(method, path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.handled[method+" "+path]
+}
+
+// onMatch registers fn to be called with the requests matching call.
+func (r *tracker) onMatch(call *mock.Call, fn func(req RequestInfo)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.callbacks == nil {
+		r.callbacks = make(map[*mock.Call][]func(req RequestInfo))
+	}
+	r.callbacks[call] = append(r.callbacks[call], fn)
+}
+
+// report returns the usage of the expectations of m.
+func (r *tracker) report(m *mock.Mock) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Report{
+		Expectations:   r.expectationUsage(m),
+		Unmatched:      append([]RequestInfo(nil), r.unmatched...),
+		UnmatchedCount: r.unmatchedCount,
+	}
+}
+
+// counts returns the usage of the expectations of m and the number of unmatched requests, without copying the
+// unmatched requests like report.
+func (r *tracker) counts(m *mock.Mock) ([]ExpectationUsage, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.expectationUsage(m), r.unmatchedCount
+}
+
+// expectationUsage returns the usage of the expectations of m, with r.mu held.
+func (r *tracker) expectationUsage(m *mock.Mock) []ExpectationUsage {
+	var usages []ExpectationUsage
+	for _, e := range expectations(m) {
+		usage := ExpectationUsage{}
+		if u := r.usage[e.call]; u != nil {
+			usage = *u
+		}
+		usage.Method = e.method
+		usage.Arguments = e.arguments
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+func (r *tracker) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usage = nil
+	r.unmatched = nil
+	r.unmatchedCount = 0
+	r.handled = nil
+	r.callbacks = nil
+	r.mismatches = nil
+}
+
+// expectation is a copy of the fields of a testify expectation that a tracker reads, taken with the mock's mutex held.
+type expectation struct {
+	call          *mock.Call
+	method        string
+	arguments     mock.Arguments
+	repeatability int
+	returns       mock.Arguments
+}
+
+// expectations returns the expectations registered on m, read with its mutex held since requests update them
+// concurrently.
+func expectations(m *mock.Mock) []expectation {
+	mu := mockMutex(m)
+	mu.Lock()
+	defer mu.Unlock()
+	expectations := make([]expectation, len(m.ExpectedCalls))
+	for i, call := range m.ExpectedCalls {
+		expectations[i] = expectation{
+			call:          call,
+			method:        call.Method,
+			arguments:     call.Arguments,
+			repeatability: call.Repeatability,
+			returns:       call.ReturnArguments,
+		}
+	}
+	return expectations
+}
+
+// mockMutex returns the mutex guarding the expectations and calls of m. testify neither exports it nor offers a locked
+// accessor for ExpectedCalls, so it is found by reflection.
+func mockMutex(m *mock.Mock) *sync.Mutex {
+	field := reflect.ValueOf(m).Elem().FieldByName("mutex")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(sync.Mutex{}) {
+		panic("httpmock: unsupported version of github.com/stretchr/testify/mock, which has no mutex field")
+	}
+	return (*sync.Mutex)(unsafe.Pointer(field.UnsafeAddr()))
+}
+
+// sameArguments reports whether the arguments of two expectations are identical. Matchers such as those of
+// mock.MatchedBy hold a func, which reflect.DeepEqual never considers equal, so they are compared by identity instead.
+func sameArguments(a mock.Arguments, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if reflect.TypeOf(a[i]) == matcherType && reflect.TypeOf(b[i]) == matcherType {
+			if a[i] != b[i] {
+				return false
+			}
+		} else if !reflect.DeepEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var matcherType = reflect.TypeOf(mock.MatchedBy(func(interface{}) bool { return true }))
+
+func formatArgs(args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case []byte:
+			parts[i] = fmt.Sprintf("%q", arg)
+		case string:
+			parts[i] = arg
+		default:
+			parts[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,117 @@
+package httpmock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStrictRegistration(t *testing.T) {
+	rt := &recordingT{}
+	m := &MockHandler{}
+	m.StrictRegistration(rt)
+
+	// Limited expectations leave room for the next ones
+	m.On("Handle", "GET", "/a", mock.Anything).Return(Response{Status: 503}).Once()
+	m.On("Handle", "GET", "/a", mock.Anything).Return(Response{Status: 200})
+	m.On("Handle", "GET", "/b", mock.Anything).Return(Response{})
+	assert.Empty(t, rt.Errors())
+
+	m.On("Handle", "GET", "/a", mock.Anything).Return(Response{Status: 404})
+	assert.Equal(t, []string{"httpmock: expectation Handle(GET, /a, mock.Anything) is shadowed by an identical one " +
+		"registered before it, so it will never be used"}, rt.Errors())
+
+	// Expectation sets register through the same method
+	rt = &recordingT{}
+	withRequest := &MockHandlerWithRequest{}
+	withRequest.StrictRegistration(rt)
+	set := NewExpectationSet()
+	set.On("HandleRequest", mock.Anything).Return(Response{})
+	set.ApplyTo(withRequest)
+	set.ApplyTo(withRequest)
+	assert.Len(t, rt.Errors(), 1)
+}
+
+func TestStrictRegistrationMatchers(t *testing.T) {
+	rt := &recordingT{}
+	m := &MockHandlerWithRequest{}
+	m.StrictRegistration(rt)
+
+	// Matchers hold funcs, so only the same matcher registered twice is identical
+	matcher := RequestMatcher(func(req RequestInfo) bool { return req.Path == "/a" })
+	m.On("HandleRequest", matcher).Return(Response{})
+	m.On("HandleRequest", RequestMatcher(func(req RequestInfo) bool { return req.Path == "/b" })).Return(Response{})
+	assert.Empty(t, rt.Errors())
+	m.On("HandleRequest", matcher).Return(Response{})
+	assert.Len(t, rt.Errors(), 1)
+
+	// Chained expectations aren't checked themselves, but later ones are checked against them
+	rt = &recordingT{}
+	chained := &MockHandler{}
+	chained.StrictRegistration(rt)
+	chained.On("Handle", "GET", "/x", mock.Anything).Return(Response{}).
+		On("Handle", "GET", "/y", mock.Anything).Return(Response{})
+	assert.Empty(t, rt.Errors())
+	chained.On("Handle", "GET", "/y", mock.Anything).Return(Response{})
+	assert.Len(t, rt.Errors(), 1)
+}
+
+func TestTrackerConcurrentRegistration(t *testing.T) {
+	downstream := &MockHandler{}
+	// Requests matching a limited expectation update it
+	downstream.On("Handle", "GET", "/busy", mock.Anything).Return(Response{}).Times(100)
+	s := NewServer(downstream)
+	defer s.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			concurrentGets(s, "/busy", 20)
+		}
+	}()
+	for i := 0; ; i++ {
+		downstream.On("Handle", "GET", "/busy", mock.Anything).Return(Response{Status: 404}).Once()
+		downstream.Report()
+		select {
+		case <-done:
+			assert.Equal(t, 100, downstream.Report().Expectations[0].Matched)
+			return
+		default:
+		}
+	}
+}
+
+func TestOnMatch(t *testing.T) {
+	downstream := &MockHandlerWithRequest{}
+	refreshed := make(chan RequestInfo, 1)
+	downstream.OnMatch(downstream.On("HandleRequest", RequestMatcher(func(req RequestInfo) bool {
+		return req.Path == "/token"
+	})).Return(Response{Body: []byte("token")}), func(req RequestInfo) {
+		refreshed <- req
+	})
+	downstream.On("HandleRequest", mock.Anything).Return(Response{})
+	s := NewServer(downstream)
+	defer s.Close()
+
+	go func() {
+		for _, path := range []string{"/other", "/token"} {
+			req, _ := http.NewRequest("POST", s.URL()+path, nil)
+			req.Header.Set("X-Client", "test")
+			if resp, err := http.DefaultClient.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}()
+	select {
+	case req := <-refreshed:
+		assert.Equal(t, "/token", req.Path)
+		assert.Equal(t, "test", req.Header.Get("X-Client"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the callback wasn't called")
+	}
+	assert.Empty(t, refreshed, "the callback should only be called for matching requests")
+}
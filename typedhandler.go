@@ -0,0 +1,40 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// TypedHandler is a Handler that decodes the JSON request body into a Req, calls Func with it, and marshals the
+// returned Resp as a JSON response with the proper Content-Type. An empty request body results in the zero value of
+// Req; a body that can't be decoded results in a 400 response without calling Func.
+type TypedHandler[Req, Resp any] struct {
+	Func func(method, path string, req Req) Resp
+}
+
+// NewTypedHandler returns a TypedHandler calling fn. It mostly exists so that Req and Resp can be inferred from fn.
+//
+//	s := httpmock.NewServer(httpmock.NewTypedHandler(func(method, path string, o Order) Receipt {
+//		return Receipt{OrderID: o.ID}
+//	}))
+func NewTypedHandler[Req, Resp any](fn func(method, path string, req Req) Resp) *TypedHandler[Req, Resp] {
+	return &TypedHandler[Req, Resp]{Func: fn}
+}
+
+// Handle makes this implement the Handler interface.
+func (h *TypedHandler[Req, Resp]) Handle(method, path string, body []byte) Response {
+	var req Req
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			return Response{
+				Status: http.StatusBadRequest,
+				Body:   []byte(fmt.Sprintf("httpmock: failed to decode request body into %T: %v", req, err)),
+			}
+		}
+	}
+	return Response{
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   ToJSON(h.Func(method, path, req)),
+	}
+}
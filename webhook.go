@@ -0,0 +1,146 @@
+package httpmock
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Webhook simulates a service calling back a webhook URL supplied by the test, such as a payment provider notifying
+// the code under test. Callbacks are sent asynchronously, either explicitly with Trigger or when the server receives
+// a triggering request, with the Middleware method. Failed deliveries (errors and non-2xx responses) are retried like
+// a real provider would.
+//
+//	hook := &httpmock.Webhook{URL: receiver.URL + "/hooks/payments", Delay: 10 * time.Millisecond, MaxAttempts: 3}
+//	s := httpmock.NewServer(downstream, httpmock.WithMiddleware(hook.Middleware(func(req httpmock.RequestInfo) bool {
+//		return req.Method == "POST" && req.Path == "/payments"
+//	})))
+//	// ... run the code under test
+//	hook.Wait()
+type Webhook struct {
+	// URL is where callbacks are sent.
+	URL string
+	// Method is the method of callbacks (default: POST).
+	Method string
+	// Header holds headers to send with every callback, e.g. a Content-Type or a signature.
+	Header http.Header
+	// Payload returns the body of the callback for a triggering request received by the server (default: the
+	// request's body).
+	Payload func(req RequestInfo) []byte
+	// Delay is how long to wait before the first attempt.
+	Delay time.Duration
+	// MaxAttempts is how many times a delivery is attempted before giving up (default: 1, i.e. no retries).
+	MaxAttempts int
+	// RetryInterval is how long to wait between attempts; it doubles after every failed attempt.
+	RetryInterval time.Duration
+	// Client sends the callbacks (default: http.DefaultClient).
+	Client *http.Client
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	attempts []WebhookAttempt
+}
+
+// WebhookAttempt is an attempt at delivering a callback.
+type WebhookAttempt struct {
+	// The payload that was sent
+	Payload []byte
+	// The attempt number, starting at 1 for each delivery
+	Attempt int
+	// When the attempt was made
+	Time time.Time
+	// The status returned by the receiver, or 0 if the request failed
+	Status int
+	// The error making the request, if any
+	Err error
+}
+
+// Middleware returns a Middleware triggering a callback for every request for which trigger returns true, once the
+// response to the request has been produced.
+func (w *Webhook) Middleware(trigger func(req RequestInfo) bool) Middleware {
+	return func(req RequestInfo, next func(RequestInfo) Response) Response {
+		resp := next(req)
+		if trigger(req) {
+			payload := req.Body
+			if w.Payload != nil {
+				payload = w.Payload(req)
+			}
+			w.Trigger(payload)
+		}
+		return resp
+	}
+}
+
+// Trigger starts delivering a callback with the given payload in the background, returning immediately.
+func (w *Webhook) Trigger(payload []byte) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.deliver(payload)
+	}()
+}
+
+// Wait waits for all triggered deliveries to succeed or give up.
+func (w *Webhook) Wait() {
+	w.wg.Wait()
+}
+
+// Attempts returns every delivery attempt made so far, in the order they were made.
+func (w *Webhook) Attempts() []WebhookAttempt {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WebhookAttempt(nil), w.attempts...)
+}
+
+func (w *Webhook) deliver(payload []byte) {
+	maxAttempts := w.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	wait := w.Delay
+	interval := w.RetryInterval
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		time.Sleep(wait)
+		wait = interval
+		interval *= 2
+
+		status, err := w.send(payload)
+		w.mu.Lock()
+		w.attempts = append(w.attempts, WebhookAttempt{
+			Payload: payload,
+			Attempt: attempt,
+			Time:    time.Now(),
+			Status:  status,
+			Err:     err,
+		})
+		w.mu.Unlock()
+		if err == nil && status >= 200 && status < 300 {
+			return
+		}
+	}
+}
+
+func (w *Webhook) send(payload []byte) (int, error) {
+	method := w.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header = cloneHeader(w.Header)
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook to %s: %v", w.URL, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,47 @@
+package httpmock
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	receiver := NewMockHandler(t)
+	receiver.On("Handle", "POST", "/hooks", []byte(`{"paid":true}`)).Return(Response{Status: 503}).Once()
+	receiver.On("Handle", "POST", "/hooks", []byte(`{"paid":true}`)).Return(Response{Status: 204}).Once()
+	r := NewServer(receiver)
+	defer r.Close()
+
+	hook := &Webhook{URL: r.URL() + "/hooks", MaxAttempts: 3, RetryInterval: time.Millisecond}
+
+	downstream := NewMockHandler(t)
+	downstream.On("Handle", "POST", "/payments", mock.Anything).Return(Response{Status: 201})
+	downstream.On("Handle", "GET", "/payments", mock.Anything).Return(Response{})
+	s := NewServer(downstream, WithMiddleware(hook.Middleware(func(req RequestInfo) bool {
+		return req.Method == "POST"
+	})))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/payments")
+	require.NoError(t, err)
+	resp.Body.Close()
+	resp, err = http.Post(s.URL()+"/payments", "application/json", strings.NewReader(`{"paid":true}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	hook.Wait()
+	attempts := hook.Attempts()
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 503, attempts[0].Status)
+	assert.Equal(t, 204, attempts[1].Status)
+	assert.Equal(t, 2, attempts[1].Attempt)
+
+	receiver.AssertExpectations(t)
+	downstream.AssertExpectations(t)
+}
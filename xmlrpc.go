@@ -0,0 +1,281 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// XML-RPC values are mapped to and from Go values as follows: <int>/<i4> to int64, <double> to float64, <boolean> to
+// bool, <string> (or an untyped value) to string, <base64> to []byte, <dateTime.iso8601> to time.Time, <array> to
+// []interface{} and <struct> to map[string]interface{}. When encoding, any integer, float, slice, string-keyed map or
+// struct (using its field names, or an `xmlrpc:"name"` tag) is accepted.
+
+const xmlrpcDateTimeFormat = "20060102T15:04:05"
+
+// XMLRPCMethodMatcher returns a mock.MatchedBy func to check if the body argument is an XML-RPC methodCall of the given
+// method with the given params.
+//
+//	downstream.On("Handle", "POST", "/RPC2", httpmock.XMLRPCMethodMatcher("examples.getStateName", 41)).Return(
+//		httpmock.XMLRPCResponse("South Dakota"))
+func XMLRPCMethodMatcher(method string, params ...interface{}) interface{} {
+	// Normalize the expected params the same way as the received ones, so e.g. int and int64 compare equal
+	_, expected, err := ParseXMLRPCCall(xmlrpcCall(method, params))
+	if err != nil {
+		panic(fmt.Sprintf("invalid XML-RPC params %v: %v", params, err))
+	}
+	return mock.MatchedBy(func(body []byte) bool {
+		actualMethod, actual, err := ParseXMLRPCCall(body)
+		return err == nil && actualMethod == method && reflect.DeepEqual(expected, actual)
+	})
+}
+
+// XMLRPCResponse returns a Response holding an XML-RPC methodResponse with the given value as its single param. It
+// panics if the value can't be encoded, so should be used only in test code.
+func XMLRPCResponse(value interface{}) Response {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header + "<methodResponse><params><param>")
+	mustEncodeXMLRPC(&buf, value)
+	buf.WriteString("</param></params></methodResponse>")
+	return xmlrpcResponse(buf.Bytes())
+}
+
+// XMLRPCFault returns a Response holding an XML-RPC fault with the given code and message.
+func XMLRPCFault(code int, message string) Response {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header + "<methodResponse><fault>")
+	mustEncodeXMLRPC(&buf, map[string]interface{}{"faultCode": code, "faultString": message})
+	buf.WriteString("</fault></methodResponse>")
+	return xmlrpcResponse(buf.Bytes())
+}
+
+func xmlrpcResponse(body []byte) Response {
+	// XML-RPC always answers 200, faults included
+	return Response{
+		Header: http.Header{"Content-Type": {"text/xml"}},
+		Body:   body,
+	}
+}
+
+// xmlrpcCall encodes a methodCall, panicking if a param can't be encoded.
+func xmlrpcCall(method string, params []interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header + "<methodCall><methodName>")
+	_ = xml.EscapeText(&buf, []byte(method))
+	buf.WriteString("</methodName><params>")
+	for _, p := range params {
+		buf.WriteString("<param>")
+		mustEncodeXMLRPC(&buf, p)
+		buf.WriteString("</param>")
+	}
+	buf.WriteString("</params></methodCall>")
+	return buf.Bytes()
+}
+
+func mustEncodeXMLRPC(buf *bytes.Buffer, v interface{}) {
+	if err := encodeXMLRPC(buf, reflect.ValueOf(v)); err != nil {
+		panic(fmt.Sprintf("failed to encode XML-RPC value %v: %v", v, err))
+	}
+}
+
+// encodeXMLRPC writes v as an XML-RPC <value> to buf.
+func encodeXMLRPC(buf *bytes.Buffer, v reflect.Value) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("XML-RPC has no nil value")
+		}
+		v = v.Elem()
+	}
+
+	buf.WriteString("<value>")
+	switch {
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		buf.WriteString("<dateTime.iso8601>" + v.Interface().(time.Time).Format(xmlrpcDateTimeFormat) + "</dateTime.iso8601>")
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		buf.WriteString("<base64>" + base64.StdEncoding.EncodeToString(v.Bytes()) + "</base64>")
+	default:
+		switch v.Kind() {
+		case reflect.Bool:
+			b := "0"
+			if v.Bool() {
+				b = "1"
+			}
+			buf.WriteString("<boolean>" + b + "</boolean>")
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buf.WriteString("<int>" + strconv.FormatInt(v.Int(), 10) + "</int>")
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			buf.WriteString("<int>" + strconv.FormatUint(v.Uint(), 10) + "</int>")
+		case reflect.Float32, reflect.Float64:
+			buf.WriteString("<double>" + strconv.FormatFloat(v.Float(), 'f', -1, 64) + "</double>")
+		case reflect.String:
+			buf.WriteString("<string>")
+			_ = xml.EscapeText(buf, []byte(v.String()))
+			buf.WriteString("</string>")
+		case reflect.Slice, reflect.Array:
+			buf.WriteString("<array><data>")
+			for i := 0; i < v.Len(); i++ {
+				if err := encodeXMLRPC(buf, v.Index(i)); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</data></array>")
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+			}
+			keys := make([]string, 0, v.Len())
+			for _, k := range v.MapKeys() {
+				keys = append(keys, k.String())
+			}
+			sort.Strings(keys)
+			buf.WriteString("<struct>")
+			for _, k := range keys {
+				if err := encodeXMLRPCMember(buf, k, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</struct>")
+		case reflect.Struct:
+			buf.WriteString("<struct>")
+			for i := 0; i < v.NumField(); i++ {
+				field := v.Type().Field(i)
+				if field.PkgPath != "" {
+					continue
+				}
+				name := field.Name
+				if tag := field.Tag.Get("xmlrpc"); tag == "-" {
+					continue
+				} else if tag != "" {
+					name = tag
+				}
+				if err := encodeXMLRPCMember(buf, name, v.Field(i)); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</struct>")
+		default:
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+	}
+	buf.WriteString("</value>")
+	return nil
+}
+
+func encodeXMLRPCMember(buf *bytes.Buffer, name string, v reflect.Value) error {
+	buf.WriteString("<member><name>")
+	_ = xml.EscapeText(buf, []byte(name))
+	buf.WriteString("</name>")
+	if err := encodeXMLRPC(buf, v); err != nil {
+		return err
+	}
+	buf.WriteString("</member>")
+	return nil
+}
+
+// xmlrpcNode is a generic XML element, used to decode XML-RPC documents.
+type xmlrpcNode struct {
+	XMLName  xml.Name
+	Text     string       `xml:",chardata"`
+	Children []xmlrpcNode `xml:",any"`
+}
+
+func (n xmlrpcNode) child(name string) (xmlrpcNode, bool) {
+	for _, c := range n.Children {
+		if c.XMLName.Local == name {
+			return c, true
+		}
+	}
+	return xmlrpcNode{}, false
+}
+
+// ParseXMLRPCCall parses an XML-RPC methodCall request body into its method name and params, which can be handy in
+// handler funcs or Captor assertions.
+func ParseXMLRPCCall(body []byte) (method string, params []interface{}, err error) {
+	var call xmlrpcNode
+	if err := xml.Unmarshal(body, &call); err != nil {
+		return "", nil, err
+	}
+	if call.XMLName.Local != "methodCall" {
+		return "", nil, fmt.Errorf("expected methodCall, got %s", call.XMLName.Local)
+	}
+	name, ok := call.child("methodName")
+	if !ok {
+		return "", nil, fmt.Errorf("methodCall has no methodName")
+	}
+
+	params = []interface{}{}
+	if p, ok := call.child("params"); ok {
+		for _, param := range p.Children {
+			value, ok := param.child("value")
+			if !ok {
+				return "", nil, fmt.Errorf("param has no value")
+			}
+			v, err := decodeXMLRPC(value)
+			if err != nil {
+				return "", nil, err
+			}
+			params = append(params, v)
+		}
+	}
+	return strings.TrimSpace(name.Text), params, nil
+}
+
+// decodeXMLRPC decodes a <value> element.
+func decodeXMLRPC(value xmlrpcNode) (interface{}, error) {
+	if len(value.Children) == 0 {
+		// A value without a type is a string
+		return value.Text, nil
+	}
+	typed := value.Children[0]
+	text := strings.TrimSpace(typed.Text)
+	switch typed.XMLName.Local {
+	case "int", "i4":
+		return strconv.ParseInt(text, 10, 64)
+	case "double":
+		return strconv.ParseFloat(text, 64)
+	case "boolean":
+		return text == "1", nil
+	case "string":
+		return typed.Text, nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(text)
+	case "dateTime.iso8601":
+		return time.Parse(xmlrpcDateTimeFormat, text)
+	case "array":
+		arr := []interface{}{}
+		data, _ := typed.child("data")
+		for _, c := range data.Children {
+			v, err := decodeXMLRPC(c)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case "struct":
+		m := map[string]interface{}{}
+		for _, member := range typed.Children {
+			name, _ := member.child("name")
+			value, ok := member.child("value")
+			if !ok {
+				return nil, fmt.Errorf("struct member %q has no value", name.Text)
+			}
+			v, err := decodeXMLRPC(value)
+			if err != nil {
+				return nil, err
+			}
+			m[name.Text] = v
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported XML-RPC type %s", typed.XMLName.Local)
+}